@@ -0,0 +1,274 @@
+package xsoar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// apiError is returned by doRequest and doUpload for responses outside the 2xx range.
+type apiError struct {
+	status string
+	body   []byte
+}
+
+func (e *apiError) Error() string {
+	return e.status
+}
+
+func (e *apiError) Body() []byte {
+	return e.body
+}
+
+// notFoundMessages are the error messages the SDK's lookups by name return when the object they searched for does not
+// exist
+var notFoundMessages = []string{
+	"not found",
+	"could not find",
+	"does not exist",
+	"doesn't exist",
+}
+
+// isNotFound reports whether a request failed because the object it addressed does not exist. A 404 response is
+// trusted for every request. Otherwise only the SDK's lookups by name qualify: they search successfully and then
+// report a missing object with an error of their own, so their message is checked when there was no response or a
+// successful one. Other failures that merely mention something missing, such as a 400 about an unknown brand, are not
+// treated as the object being gone.
+func isNotFound(httpResponse *http.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	if httpResponse != nil && httpResponse.StatusCode == http.StatusNotFound {
+		return true
+	}
+	sdkErr, ok := err.(openapi.GenericOpenAPIError)
+	if !ok {
+		return false
+	}
+	if httpResponse != nil && (httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300) {
+		return false
+	}
+	message := strings.ToLower(sdkErr.Error() + " " + string(sdkErr.Body()))
+	for _, notFound := range notFoundMessages {
+		if strings.Contains(message, notFound) {
+			return true
+		}
+	}
+	return false
+}
+
+// xsoarError is the body XSOAR sends with a failed request.
+type xsoarError struct {
+	Id     interface{} `json:"id"`
+	Error  string      `json:"error"`
+	Detail string      `json:"detail"`
+}
+
+// apiErrorMessage returns the error XSOAR sent in the body of a failed request, or "" if err carries none.
+func apiErrorMessage(err error) string {
+	var body []byte
+	var openapiErr openapi.GenericOpenAPIError
+	var doRequestErr *apiError
+	if errors.As(err, &openapiErr) {
+		body = openapiErr.Body()
+	} else if errors.As(err, &doRequestErr) {
+		body = doRequestErr.Body()
+	}
+	var xErr xsoarError
+	if len(body) == 0 || json.Unmarshal(body, &xErr) != nil {
+		return ""
+	}
+	message := xErr.Error
+	if xErr.Detail != "" && xErr.Detail != xErr.Error {
+		if message != "" {
+			message += ": "
+		}
+		message += xErr.Detail
+	}
+	if message == "" {
+		return ""
+	}
+	if xErr.Id != nil {
+		message += fmt.Sprintf(" (error %v)", xErr.Id)
+	}
+	return message
+}
+
+// apiErrorDetail renders the detail of a diagnostic for a failed request, followed by the XSOAR error if there is one.
+func apiErrorDetail(detail string, err error) string {
+	detail += ": " + err.Error()
+	if message := apiErrorMessage(err); message != "" {
+		detail += "\n\nXSOAR returned: " + message
+	}
+	return detail
+}
+
+// addAPIError adds an error diagnostic for a failed request.
+func addAPIError(diags *diag.Diagnostics, summary string, detail string, err error) {
+	diags.AddError(summary, apiErrorDetail(detail, err))
+}
+
+// addAPIAttributeError adds an error diagnostic for a failed request that concerns a single attribute.
+func addAPIAttributeError(diags *diag.Diagnostics, attributePath path.Path, summary string, detail string, err error) {
+	diags.AddAttributeError(attributePath, summary, apiErrorDetail(detail, err))
+}
+
+// doRequest sends a request for an endpoint the SDK does not cover, using the SDK client's server URL, default
+// headers and HTTP client. body, if not nil, is sent as JSON and the response is decoded into out if it is not nil.
+func doRequest(ctx context.Context, client *openapi.APIClient, method string, path string, body interface{}, out interface{}) (*http.Response, error) {
+	var reqBody io.Reader
+	var contentType string
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(b)
+		contentType = "application/json"
+	}
+	return sendRequest(ctx, client, method, path, contentType, reqBody, out)
+}
+
+// doUpload posts content as the file of a multipart form, as the endpoints that import content from a file expect,
+// decoding the response into out if it is not nil.
+func doUpload(ctx context.Context, client *openapi.APIClient, path string, fileName string, content []byte, out interface{}) (*http.Response, error) {
+	var reqBody bytes.Buffer
+	form := multipart.NewWriter(&reqBody)
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(content); err != nil {
+		return nil, err
+	}
+	if err = form.Close(); err != nil {
+		return nil, err
+	}
+	return sendRequest(ctx, client, http.MethodPost, path, form.FormDataContentType(), &reqBody, out)
+}
+
+// sendRequest sends a request with the SDK client's server URL, default headers and HTTP client, returning an apiError
+// for responses outside the 2xx range. The response body can still be read after it returns.
+func sendRequest(ctx context.Context, client *openapi.APIClient, method string, path string, contentType string, reqBody io.Reader, out interface{}) (*http.Response, error) {
+	config := client.GetConfig()
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(config.Servers[0].URL, "/")+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range config.DefaultHeader {
+		req.Header.Set(key, value)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewBuffer(respBody))
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode >= 300 {
+		return resp, &apiError{status: resp.Status, body: respBody}
+	}
+	if out != nil && len(respBody) > 0 {
+		if err = json.Unmarshal(respBody, out); err != nil {
+			return resp, fmt.Errorf("could not decode response: %s", err)
+		}
+	}
+	return resp, nil
+}
+
+// serverInfo describes the server the provider is talking to, as reported by its /about endpoint.
+type serverInfo struct {
+	Version      string
+	BuildNumber  string
+	MajorVersion int64
+	MinorVersion int64
+	Capabilities map[string]bool
+}
+
+// Capabilities that vary between XSOAR releases. Resources use these to adjust their payloads or to fail early with a
+// clear diagnostic rather than an opaque API error.
+const (
+	// capabilityMultiTenantHosts is the multi-tenant host, HA group and account management of XSOAR 6 main hosts
+	capabilityMultiTenantHosts = "multi_tenant_hosts"
+	// capabilityInstanceVersionOverride is saving integration instances with version -1 to skip the optimistic lock
+	capabilityInstanceVersionOverride = "instance_version_override"
+)
+
+func newServerInfo(version string, buildNumber string) *serverInfo {
+	info := &serverInfo{
+		Version:      version,
+		BuildNumber:  buildNumber,
+		Capabilities: map[string]bool{},
+	}
+	_, _ = fmt.Sscanf(normalizeVersion(version), "%d.%d", &info.MajorVersion, &info.MinorVersion)
+	if info.MajorVersion < 8 {
+		info.Capabilities[capabilityMultiTenantHosts] = true
+		info.Capabilities[capabilityInstanceVersionOverride] = true
+	}
+	return info
+}
+
+// normalizeVersion reduces a version to its major.minor.patch release, dropping a leading "v" and any build suffix, so
+// that "v6.10", "6.10.0-123456" and "6.10.0.123456" all normalize to "6.10.0".
+func normalizeVersion(version string) string {
+	version = strings.TrimLeft(strings.TrimSpace(version), "vV")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+	if len(version) == 0 {
+		return ""
+	}
+	parts := strings.Split(version, ".")
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	return strings.Join(parts[:3], ".")
+}
+
+// sameVersion reports whether two versions are the same release, ignoring how each is formatted.
+func sameVersion(a string, b string) bool {
+	return normalizeVersion(a) == normalizeVersion(b)
+}
+
+// supports reports whether the server has a capability. Unknown servers are assumed to support everything, so a failed
+// version lookup never blocks an apply.
+func (s *serverInfo) supports(capability string) bool {
+	if s == nil {
+		return true
+	}
+	return s.Capabilities[capability]
+}
+
+// getServerInfo queries the /about endpoint of the main server.
+func getServerInfo(ctx context.Context, client *openapi.APIClient) (*serverInfo, error) {
+	var about map[string]interface{}
+	_, err := doRequest(ctx, client, http.MethodGet, "/about", nil, &about)
+	if err != nil {
+		return nil, err
+	}
+	version, ok := about["demistoVersion"].(string)
+	if !ok {
+		return nil, fmt.Errorf("server version missing from /about")
+	}
+	buildNumber, _ := about["buildNum"].(string)
+	return newServerInfo(version, buildNumber), nil
+}
@@ -0,0 +1,107 @@
+package xsoar
+
+import (
+	"context"
+	"errors"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// sdkResult makes a request with the SDK against a server that answers every request with status and body, returning
+// the response and error the SDK gives back for it
+func sdkResult(t *testing.T, status int, body string, request func(*openapi.APIClient) (*http.Response, error)) (*http.Response, error) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	config := openapi.NewConfiguration()
+	config.Servers[0].URL = server.URL
+	return request(openapi.NewAPIClient(config))
+}
+
+func getHAGroup(client *openapi.APIClient) (*http.Response, error) {
+	_, resp, err := client.DefaultApi.GetHAGroup(context.Background(), "1").Execute()
+	return resp, err
+}
+
+func getClassifier(client *openapi.APIClient) (*http.Response, error) {
+	_, resp, err := client.DefaultApi.GetClassifier(context.Background()).SetIdentifier("missing").Execute()
+	return resp, err
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		request func(*openapi.APIClient) (*http.Response, error)
+		want    bool
+	}{
+		{"sdk 404", 404, `{"error":"Item not found"}`, getHAGroup, true},
+		{"sdk 404 without message", 404, ``, getHAGroup, true},
+		{"sdk lookup by name finds nothing", 200, `{"classifiers":[]}`, getClassifier, true},
+		{"sdk 400 mentioning something missing", 400, `{"error":"brand threatcentral not found"}`, getHAGroup, false},
+		{"sdk 500 mentioning something missing", 500, `{"error":"index does not exist"}`, getHAGroup, false},
+		{"sdk 403", 403, `{"error":"forbidden"}`, getHAGroup, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := sdkResult(t, tt.status, tt.body, tt.request)
+			if err == nil {
+				t.Fatalf("request succeeded, want an error")
+			}
+			if got := isNotFound(resp, err); got != tt.want {
+				t.Errorf("isNotFound(%d, %q) = %t, want %t", tt.status, err, got, tt.want)
+			}
+		})
+	}
+
+	response := func(status int) *http.Response { return &http.Response{StatusCode: status} }
+	others := []struct {
+		name string
+		resp *http.Response
+		err  error
+		want bool
+	}{
+		{"no error", response(404), nil, false},
+		{"request 404", response(404), &apiError{status: "404 Not Found"}, true},
+		{"request 400 mentioning something missing", response(400), &apiError{status: "400 Bad Request", body: []byte(`{"error":"not found"}`)}, false},
+		{"connection error mentioning something missing", nil, errors.New("dial tcp: lookup xsoar: host not found"), false},
+	}
+	for _, tt := range others {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNotFound(tt.resp, tt.err); got != tt.want {
+				t.Errorf("isNotFound(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"6.10.0", "6.10.0"},
+		{"v6.10.0", "6.10.0"},
+		{"6.10", "6.10.0"},
+		{"6", "6.0.0"},
+		{"6.10.0-123456", "6.10.0"},
+		{"6.10.0.123456", "6.10.0"},
+		{"6.10.0+build.7", "6.10.0"},
+		{" 6.10.0 ", "6.10.0"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := normalizeVersion(tt.version); got != tt.want {
+				t.Errorf("normalizeVersion(%q) = %q, want %q", tt.version, got, tt.want)
+			}
+		})
+	}
+}
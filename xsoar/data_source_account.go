@@ -4,8 +4,10 @@ import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"time"
 )
 
 type dataSourceAccountType struct{}
@@ -42,6 +44,9 @@ func (r dataSourceAccountType) GetSchema(_ context.Context) (tfsdk.Schema, diag.
 				Computed: true,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -56,6 +61,7 @@ type dataSourceAccount struct {
 }
 
 func (r dataSourceAccount) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_account")
 	// Declare struct that this function will set to this data source's config
 	var config Account
 	diags := req.Config.Get(ctx, &config)
@@ -63,6 +69,8 @@ func (r dataSourceAccount) Read(ctx context.Context, req tfsdk.ReadDataSourceReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, config.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get account from API and then update what is in config from what the API returns
 	accName := "acc_" + config.Name.Value
@@ -70,10 +78,7 @@ func (r dataSourceAccount) Read(ctx context.Context, req tfsdk.ReadDataSourceReq
 	// Get account current value
 	account, _, err := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting account",
-			"Could not read account "+accName+": "+err.Error(),
-		)
+		addAPIAttributeError(&resp.Diagnostics, path.Root("name"), "Error getting account", "Could not read account "+accName, err)
 		return
 	}
 
@@ -92,10 +97,7 @@ func (r dataSourceAccount) Read(ctx context.Context, req tfsdk.ReadDataSourceReq
 
 	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing account details",
-			"Could not read account details"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing account details", "Could not read account details", err)
 		return
 	}
 	var roles []attr.Value
@@ -115,10 +117,7 @@ func (r dataSourceAccount) Read(ctx context.Context, req tfsdk.ReadDataSourceReq
 	}
 	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not read HA groups", err)
 		return
 	}
 	var hostGroupName = ""
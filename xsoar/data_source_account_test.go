@@ -3,7 +3,7 @@ package xsoar
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -18,7 +18,7 @@ func TestAccAccountDataSource_basic(t *testing.T) {
 		PreCheck: func() { testAccAccountDataSourcePreCheck(t) },
 		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
 			"xsoar": func() (tfprotov6.ProviderServer, error) {
-				return tfsdk.NewProtocol6Server(New()), nil
+				return providerserver.NewProtocol6WithError(New()())()
 			},
 		},
 		CheckDestroy: testAccCheckAccountDataSourceDestroy(rName),
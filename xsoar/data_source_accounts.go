@@ -43,6 +43,7 @@ type dataSourceAccounts struct {
 }
 
 func (r dataSourceAccounts) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_accounts")
 	// Declare struct that this function will set to this data source's config
 	var config Accounts
 	diags := req.Config.Get(ctx, &config)
@@ -54,26 +55,17 @@ func (r dataSourceAccounts) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 	// Get accounts current value
 	accounts, _, err := r.p.client.DefaultApi.ListAccounts(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting accounts",
-			"Could not read accounts: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting accounts", "Could not read accounts", err)
 		return
 	}
 	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing account details",
-			"Could not read account details"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing account details", "Could not read account details", err)
 		return
 	}
 	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not read HA groups", err)
 		return
 	}
 
@@ -6,11 +6,10 @@ import (
 	"github.com/badarsebard/xsoar-sdk-go/openapi"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
-	"log"
-	"net/http"
+	"time"
 )
 
 type dataSourceClassifierType struct{}
@@ -53,6 +52,9 @@ func (r dataSourceClassifierType) GetSchema(_ context.Context) (tfsdk.Schema, di
 				Optional: false,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -67,6 +69,7 @@ type dataSourceClassifier struct {
 }
 
 func (r dataSourceClassifier) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_classifier")
 	// Declare struct that this function will set to this data source's config
 	var config Classifier
 	diags := req.Config.Get(ctx, &config)
@@ -74,27 +77,19 @@ func (r dataSourceClassifier) Read(ctx context.Context, req tfsdk.ReadDataSource
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, config.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get resource from API
 	var classifier openapi.InstanceClassifier
-	var httpResponse *http.Response
 	var err error
-	if config.Account.Null || len(config.Account.Value) == 0 {
-		classifier, httpResponse, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(config.Name.Value).Execute()
+	if accName, ok := r.p.accountName(config.Account); !ok {
+		classifier, _, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(config.Name.Value).Execute()
 	} else {
-		classifier, httpResponse, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+config.Account.Value).SetIdentifier(config.Name.Value).Execute()
-	}
-	if httpResponse != nil {
-		getBody, _ := httpResponse.Request.GetBody()
-		b, _ := io.ReadAll(getBody)
-		log.Println(string(b))
+		classifier, _, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, accName).SetIdentifier(config.Name.Value).Execute()
 	}
 	if err != nil {
-		log.Println(err.Error())
-		resp.Diagnostics.AddError(
-			"Error getting classifier",
-			"Could not get classifier: "+err.Error(),
-		)
+		addAPIAttributeError(&resp.Diagnostics, path.Root("name"), "Error getting classifier", "Could not get classifier", err)
 		return
 	}
 
@@ -150,6 +145,8 @@ func (r dataSourceClassifier) Read(ctx context.Context, req tfsdk.ReadDataSource
 		result.Transformer = types.String{Value: v}
 	}
 
+	result.Timeouts = config.Timeouts
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -3,8 +3,10 @@ package xsoar
 import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"time"
 )
 
 type dataSourceHAGroupType struct{}
@@ -37,6 +39,9 @@ func (r dataSourceHAGroupType) GetSchema(_ context.Context) (tfsdk.Schema, diag.
 				Computed: true,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -51,6 +56,7 @@ type dataSourceHAGroup struct {
 }
 
 func (r dataSourceHAGroup) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_ha_group")
 	// Declare struct that this function will set to this data source's config
 	var config HAGroup
 	diags := req.Config.Get(ctx, &config)
@@ -58,14 +64,13 @@ func (r dataSourceHAGroup) Read(ctx context.Context, req tfsdk.ReadDataSourceReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, config.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get HA group from API and then update what is in config from what the API returns
 	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not list HA groups: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not list HA groups", err)
 		return
 	}
 	var haGroupId string
@@ -77,10 +82,7 @@ func (r dataSourceHAGroup) Read(ctx context.Context, req tfsdk.ReadDataSourceReq
 	}
 	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, haGroupId).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting HA group",
-			"Could not get HA group "+config.Name.Value+": "+err.Error(),
-		)
+		addAPIAttributeError(&resp.Diagnostics, path.Root("name"), "Error getting HA group", "Could not get HA group "+config.Name.Value, err)
 		return
 	}
 
@@ -52,6 +52,7 @@ type dataSourceHAGroups struct {
 }
 
 func (r dataSourceHAGroups) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_ha_groups")
 	// Declare struct that this function will set to this data source's config
 	var config HAGroups
 	diags := req.Config.Get(ctx, &config)
@@ -63,10 +64,7 @@ func (r dataSourceHAGroups) Read(ctx context.Context, req tfsdk.ReadDataSourceRe
 	// Get HA group from API and then update what is in config from what the API returns
 	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not list HA groups: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not list HA groups", err)
 		return
 	}
 
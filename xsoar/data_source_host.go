@@ -3,6 +3,7 @@ package xsoar
 import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"time"
@@ -27,6 +28,10 @@ func (r dataSourceHostType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Dia
 				Computed: true,
 				Optional: true,
 			},
+			"installed_version": {
+				Type:     types.StringType,
+				Computed: true,
+			},
 			"elasticsearch_url": {
 				Type:     types.StringType,
 				Computed: true,
@@ -43,11 +48,15 @@ func (r dataSourceHostType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Dia
 				Optional: true,
 			},
 			"ssh_key": {
-				Type:     types.StringType,
-				Computed: false,
-				Optional: true,
+				Type:      types.StringType,
+				Computed:  false,
+				Optional:  true,
+				Sensitive: true,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -62,39 +71,22 @@ type dataSourceHost struct {
 }
 
 func (r dataSourceHost) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_host")
 	// Declare struct that this function will set to this data source's config
-	var config Host
+	var config HostDataSource
 	diags := req.Config.Get(ctx, &config)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var host map[string]interface{}
-	var err error
-	c1 := make(chan map[string]interface{}, 1)
-	go func() {
-		for host == nil {
-			host, _, err = r.p.client.DefaultApi.GetHost(ctx, config.Name.Value).Execute()
-			if err != nil {
-				resp.Diagnostics.AddError(
-					"Error listing HA groups",
-					"Could not list HA groups: "+err.Error(),
-				)
-				return
-			}
-			time.Sleep(time.Second)
-		}
-		c1 <- host
-	}()
-	select {
-	case _ = <-c1:
-		break
-	case <-time.After(300 * time.Second):
-		resp.Diagnostics.AddError(
-			"Error getting host",
-			"Could not get host before timeout",
-		)
+	ctx, cancel := withTimeout(ctx, config.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
+
+	// The host may not have registered with the server yet, so wait for it until the read timeout
+	host, err := waitForHost(ctx, r.p.client, config.Name.Value)
+	if err != nil {
+		addAPIAttributeError(&resp.Diagnostics, path.Root("name"), "Error getting host", "Could not get host", err)
 		return
 	}
 
@@ -105,15 +97,12 @@ func (r dataSourceHost) Read(ctx context.Context, req tfsdk.ReadDataSourceReques
 
 	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, haGroupId).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting HA group",
-			"Could not get HA group: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting HA group", "Could not get HA group", err)
 		return
 	}
 
-	var result Host
-	result = Host{
+	var result HostDataSource
+	result = HostDataSource{
 		Name: types.String{Value: hostName},
 		Id:   types.String{Value: hostId},
 	}
@@ -138,6 +127,9 @@ func (r dataSourceHost) Read(ctx context.Context, req tfsdk.ReadDataSourceReques
 	result.ServerUrl = config.ServerUrl
 	result.SSHUser = config.SSHUser
 	result.SSHKey = config.SSHKey
+	result.InstalledVersion = hostVersion(host)
+
+	result.Timeouts = config.Timeouts
 
 	// Set state
 	diags = resp.State.Set(ctx, result)
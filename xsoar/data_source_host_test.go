@@ -3,7 +3,7 @@ package xsoar
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -19,7 +19,7 @@ func TestAccHostDataSource_basic(t *testing.T) {
 		PreCheck: func() { testAccHostDataSourcePreCheck(t) },
 		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
 			"xsoar": func() (tfprotov6.ProviderServer, error) {
-				return tfsdk.NewProtocol6Server(New()), nil
+				return providerserver.NewProtocol6WithError(New()())()
 			},
 		},
 		CheckDestroy: testAccCheckHostDataSourceDestroy(rName),
@@ -32,7 +32,12 @@ func TestAccHostDataSource_basic(t *testing.T) {
 	})
 }
 
-func testAccHostDataSourcePreCheck(t *testing.T) {}
+func testAccHostDataSourcePreCheck(t *testing.T) {
+	// hosts are installed over SSH and register themselves with the main server, which the fake server cannot do
+	if os.Getenv("DEMISTO_HOST") == "" {
+		t.Skip("DEMISTO_HOST must be set to run host acceptance tests")
+	}
+}
 
 func testAccCheckHostDataSourceExists(r string) resource.TestCheckFunc {
 	return func(state *terraform.State) error {
@@ -5,12 +5,11 @@ import (
 	"encoding/json"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
-	"log"
-	"net/http"
 	"reflect"
+	"time"
 )
 
 type dataSourceIntegrationInstanceType struct{}
@@ -59,6 +58,9 @@ func (r dataSourceIntegrationInstanceType) GetSchema(_ context.Context) (tfsdk.S
 				Required: true,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -73,6 +75,7 @@ type dataSourceIntegrationInstance struct {
 }
 
 func (r dataSourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_integration_instance")
 	// Get current config
 	var config IntegrationInstance
 	diags := req.Config.Get(ctx, &config)
@@ -80,27 +83,19 @@ func (r dataSourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadD
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, config.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get resource from API
 	var integration map[string]interface{}
-	var httpResponse *http.Response
 	var err error
-	if config.Account.Null || len(config.Account.Value) == 0 {
-		integration, httpResponse, err = r.p.client.DefaultApi.GetIntegrationInstance(ctx).SetIdentifier(config.Name.Value).Execute()
+	if accName, ok := r.p.accountName(config.Account); !ok {
+		integration, _, err = r.p.client.DefaultApi.GetIntegrationInstance(ctx).SetIdentifier(config.Name.Value).Execute()
 	} else {
-		integration, httpResponse, err = r.p.client.DefaultApi.GetIntegrationInstanceAccount(ctx, "acc_"+config.Account.Value).SetIdentifier(config.Name.Value).Execute()
-	}
-	if httpResponse != nil {
-		getBody := httpResponse.Body
-		b, _ := io.ReadAll(getBody)
-		log.Println(string(b))
+		integration, _, err = r.p.client.DefaultApi.GetIntegrationInstanceAccount(ctx, accName).SetIdentifier(config.Name.Value).Execute()
 	}
 	if err != nil {
-		log.Println(err.Error())
-		resp.Diagnostics.AddError(
-			"Error getting integration instance",
-			"Could not get integration instance: "+err.Error(),
-		)
+		addAPIAttributeError(&resp.Diagnostics, path.Root("name"), "Error getting integration instance", "Could not get integration instance", err)
 		return
 	}
 
@@ -120,7 +115,6 @@ func (r dataSourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadD
 	integrationConfigs := make(map[string]any)
 	if integration["data"] == nil {
 		integrationConfigs = map[string]any{}
-		log.Println(integrationConfigs)
 	} else {
 		var integrationConfig map[string]interface{}
 		switch reflect.TypeOf(integration["data"]).Kind() {
@@ -128,7 +122,6 @@ func (r dataSourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadD
 			s := reflect.ValueOf(integration["data"])
 			for i := 0; i < s.Len(); i++ {
 				integrationConfig = s.Index(i).Interface().(map[string]interface{})
-				log.Println(integrationConfig)
 				nameconf, ok := integrationConfig["name"].(string)
 				if ok {
 					integrationConfigs[nameconf] = integrationConfig["value"]
@@ -178,6 +171,8 @@ func (r dataSourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadD
 		result.EngineId = types.String{Null: true}
 	}
 
+	result.Timeouts = config.Timeouts
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
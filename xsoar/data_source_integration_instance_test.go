@@ -3,7 +3,7 @@ package xsoar
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -18,7 +18,7 @@ func TestAccIntegrationInstanceDataSource_basic(t *testing.T) {
 		PreCheck: func() { testAccIntegrationInstanceDataSourcePreCheck(t) },
 		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
 			"xsoar": func() (tfprotov6.ProviderServer, error) {
-				return tfsdk.NewProtocol6Server(New()), nil
+				return providerserver.NewProtocol6WithError(New()())()
 			},
 		},
 		CheckDestroy: testAccCheckIntegrationInstanceDataSourceDestroy(rName),
@@ -61,11 +61,11 @@ resource "xsoar_integration_instance" "{name}" {
   name               = "{name}"
   integration_name   = "threatcentral"
   propagation_labels = ["all"]
-  config = {
+  config_json = jsonencode({
     APIAddress : "https://threatcentral.io/tc/rest/summaries"
     APIKey : "123"
     useproxy : "true"
-  }
+  })
 }
 
 data "xsoar_integration_instance" "{name}" {
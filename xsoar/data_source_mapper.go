@@ -6,11 +6,10 @@ import (
 	"github.com/badarsebard/xsoar-sdk-go/openapi"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
-	"log"
-	"net/http"
+	"time"
 )
 
 type dataSourceMapperType struct{}
@@ -48,6 +47,9 @@ func (r dataSourceMapperType) GetSchema(_ context.Context) (tfsdk.Schema, diag.D
 				Optional: false,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -62,6 +64,7 @@ type dataSourceMapper struct {
 }
 
 func (r dataSourceMapper) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_mapper")
 	// Declare struct that this function will set to this data source's config
 	var config Mapper
 	diags := req.Config.Get(ctx, &config)
@@ -69,27 +72,19 @@ func (r dataSourceMapper) Read(ctx context.Context, req tfsdk.ReadDataSourceRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, config.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get resource from API
 	var mapper openapi.InstanceClassifier
-	var httpResponse *http.Response
 	var err error
-	if config.Account.Null || len(config.Account.Value) == 0 {
-		mapper, httpResponse, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(config.Name.Value).Execute()
+	if accName, ok := r.p.accountName(config.Account); !ok {
+		mapper, _, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(config.Name.Value).Execute()
 	} else {
-		mapper, httpResponse, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+config.Account.Value).SetIdentifier(config.Name.Value).Execute()
-	}
-	if httpResponse != nil {
-		getBody, _ := httpResponse.Request.GetBody()
-		b, _ := io.ReadAll(getBody)
-		log.Println(string(b))
+		mapper, _, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, accName).SetIdentifier(config.Name.Value).Execute()
 	}
 	if err != nil {
-		log.Println(err.Error())
-		resp.Diagnostics.AddError(
-			"Error creating classifier",
-			"Could not create classifier: "+err.Error(),
-		)
+		addAPIAttributeError(&resp.Diagnostics, path.Root("name"), "Error getting mapper", "Could not get mapper", err)
 		return
 	}
 	var propLabels []attr.Value
@@ -119,6 +114,8 @@ func (r dataSourceMapper) Read(ctx context.Context, req tfsdk.ReadDataSourceRequ
 		result.Mapping = types.String{Value: m}
 	}
 
+	result.Timeouts = config.Timeouts
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
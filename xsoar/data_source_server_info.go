@@ -0,0 +1,93 @@
+package xsoar
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sort"
+)
+
+type dataSourceServerInfoType struct{}
+
+func (r dataSourceServerInfoType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"id": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"version": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"build_number": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"major_version": {
+				Type:     types.Int64Type,
+				Computed: true,
+			},
+			"minor_version": {
+				Type:     types.Int64Type,
+				Computed: true,
+			},
+			"capabilities": {
+				Type:     types.SetType{ElemType: types.StringType},
+				Computed: true,
+			},
+		},
+	}, nil
+}
+
+func (r dataSourceServerInfoType) NewDataSource(_ context.Context, p tfsdk.Provider) (tfsdk.DataSource, diag.Diagnostics) {
+	return dataSourceServerInfo{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+type dataSourceServerInfo struct {
+	p provider
+}
+
+func (r dataSourceServerInfo) Read(ctx context.Context, req tfsdk.ReadDataSourceRequest, resp *tfsdk.ReadDataSourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_server_info")
+	// Use the server info looked up when the provider was configured, if there is any
+	server := r.p.data.Server
+	if server == nil {
+		var err error
+		server, err = getServerInfo(ctx, r.p.client)
+		if err != nil {
+			addAPIError(&resp.Diagnostics, "Error getting server info", "Could not get server info", err)
+			return
+		}
+	}
+
+	// Map response body to data source schema attribute
+	var capabilities []string
+	for capability, ok := range server.Capabilities {
+		if ok {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	sort.Strings(capabilities)
+	result := ServerInfo{
+		Id:           types.String{Value: server.Version},
+		Version:      types.String{Value: server.Version},
+		BuildNumber:  types.String{Value: server.BuildNumber},
+		MajorVersion: types.Int64{Value: server.MajorVersion},
+		MinorVersion: types.Int64{Value: server.MinorVersion},
+		Capabilities: types.Set{ElemType: types.StringType},
+	}
+	for _, capability := range capabilities {
+		result.Capabilities.Elems = append(result.Capabilities.Elems, types.String{Value: capability})
+	}
+
+	// Set state
+	diags := resp.State.Set(ctx, &result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
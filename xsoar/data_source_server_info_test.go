@@ -0,0 +1,36 @@
+package xsoar
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"testing"
+)
+
+func TestAccServerInfoDataSource_basic(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccServerInfoDataSourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccServerInfoDataSourceBasic(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.xsoar_server_info.test", "version"),
+					resource.TestCheckResourceAttrSet("data.xsoar_server_info.test", "major_version"),
+				),
+			},
+		},
+	})
+}
+
+func testAccServerInfoDataSourcePreCheck(t *testing.T) {}
+
+func testAccServerInfoDataSourceBasic() string {
+	return `
+data "xsoar_server_info" "test" {}
+`
+}
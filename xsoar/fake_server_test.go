@@ -0,0 +1,613 @@
+package xsoar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	fakeAPIKey  = "fake-api-key"
+	fakeVersion = "6.10.0"
+)
+
+// fakeServer is an in-process, stateful stand-in for the multi-tenant endpoints of an XSOAR main host. It is used by
+// the acceptance tests whenever DEMISTO_BASE_URL is not set, so they can run without a live tenant.
+type fakeServer struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	nextId        int
+	accounts      map[string]map[string]interface{}
+	haGroups      map[string]map[string]interface{}
+	hosts         map[string]map[string]interface{}
+	classifiers   map[string]map[string]map[string]interface{}
+	instances     map[string]map[string]map[string]interface{}
+	incidentTypes map[string]map[string]map[string]interface{}
+	fields        map[string]map[string]map[string]interface{}
+	reputations   map[string]map[string]map[string]interface{}
+	layouts       map[string]map[string]map[string]interface{}
+	playbooks     map[string]map[string]map[string]interface{}
+	playbookYAML  map[string]map[string]string
+	integrations  []interface{}
+}
+
+func newFakeServer() *fakeServer {
+	f := &fakeServer{
+		accounts:      map[string]map[string]interface{}{},
+		haGroups:      map[string]map[string]interface{}{},
+		hosts:         map[string]map[string]interface{}{},
+		classifiers:   map[string]map[string]map[string]interface{}{"": {}},
+		instances:     map[string]map[string]map[string]interface{}{"": {}},
+		incidentTypes: map[string]map[string]map[string]interface{}{"": {}},
+		fields:        map[string]map[string]map[string]interface{}{"": {}},
+		reputations:   map[string]map[string]map[string]interface{}{"": {}},
+		layouts:       map[string]map[string]map[string]interface{}{"": {}},
+		playbooks:     map[string]map[string]map[string]interface{}{"": {}},
+		playbookYAML:  map[string]map[string]string{"": {}},
+		integrations: []interface{}{
+			map[string]interface{}{
+				"name":              "threatcentral",
+				"category":          "Data Enrichment & Threat Intelligence",
+				"canGetSamples":     false,
+				"integrationScript": nil,
+				"configuration": []interface{}{
+					map[string]interface{}{"name": "APIAddress", "display": "API Address", "defaultValue": "", "type": 0},
+					map[string]interface{}{"name": "APIKey", "display": "API Key", "defaultValue": "", "type": 4},
+					map[string]interface{}{"name": "useproxy", "display": "Use system proxy settings", "defaultValue": "false", "type": 8},
+				},
+			},
+		},
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakeServer) id() string {
+	f.nextId++
+	return fmt.Sprintf("%08d-fake", f.nextId)
+}
+
+func (f *fakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != fakeAPIKey {
+		writeFakeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]interface{}
+	if r.Body != nil && !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	// account scoped requests are prefixed with /acc_<name>
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	account := ""
+	if strings.HasPrefix(segments[0], "acc_") {
+		account = strings.TrimPrefix(segments[0], "acc_")
+		if _, ok := f.accounts[account]; !ok {
+			writeFakeError(w, http.StatusNotFound, "account not found: "+account)
+			return
+		}
+		segments = segments[1:]
+	}
+	route := r.Method + " " + strings.Join(segments, "/")
+
+	switch {
+	case account == "" && route == "GET about":
+		writeFakeJSON(w, map[string]interface{}{"demistoVersion": fakeVersion, "buildNum": "123456"})
+	case account == "" && route == "GET accounts":
+		writeFakeJSON(w, f.listAccounts())
+	case account == "" && route == "GET accounts/data":
+		details := map[string]interface{}{}
+		for _, acc := range f.accounts {
+			var roles []interface{}
+			for _, role := range acc["roles"].(map[string]interface{})["roles"].([]interface{}) {
+				roles = append(roles, map[string]interface{}{"name": role})
+			}
+			details[acc["id"].(string)] = map[string]interface{}{"name": acc["name"], "roles": roles}
+		}
+		writeFakeJSON(w, details)
+	case account == "" && route == "POST account":
+		name, _ := body["name"].(string)
+		if _, ok := f.accounts[name]; ok {
+			writeFakeError(w, http.StatusBadRequest, "account already exists: "+name)
+			return
+		}
+		f.accounts[name] = map[string]interface{}{
+			"id":                f.id(),
+			"name":              "acc_" + name,
+			"displayName":       name,
+			"hostGroupId":       stringOr(body["hostGroupId"], ""),
+			"status":            "ready",
+			"propagationLabels": listOr(body["propagationLabels"]),
+			"roles":             map[string]interface{}{"roles": listOr(body["accountRoles"])},
+		}
+		f.classifiers[name] = map[string]map[string]interface{}{}
+		f.instances[name] = map[string]map[string]interface{}{}
+		f.incidentTypes[name] = map[string]map[string]interface{}{}
+		f.fields[name] = map[string]map[string]interface{}{}
+		f.reputations[name] = map[string]map[string]interface{}{}
+		f.layouts[name] = map[string]map[string]interface{}{}
+		f.playbooks[name] = map[string]map[string]interface{}{}
+		f.playbookYAML[name] = map[string]string{}
+		writeFakeJSON(w, f.listAccounts())
+	case account == "" && len(segments) == 3 && r.Method == "DELETE" && segments[0] == "account" && segments[1] == "purge":
+		name := strings.TrimPrefix(segments[2], "acc_")
+		if _, ok := f.accounts[name]; !ok {
+			writeFakeError(w, http.StatusNotFound, "account not found: "+name)
+			return
+		}
+		delete(f.accounts, name)
+		delete(f.classifiers, name)
+		delete(f.instances, name)
+		delete(f.incidentTypes, name)
+		delete(f.fields, name)
+		delete(f.reputations, name)
+		delete(f.layouts, name)
+		delete(f.playbooks, name)
+		delete(f.playbookYAML, name)
+		writeFakeJSON(w, f.listAccounts())
+	case account == "" && len(segments) == 3 && r.Method == "POST" && segments[0] == "account" && segments[1] == "update":
+		acc, ok := f.accounts[strings.TrimPrefix(segments[2], "acc_")]
+		if !ok {
+			writeFakeError(w, http.StatusNotFound, "account not found: "+segments[2])
+			return
+		}
+		if roles, ok := body["selectedRoles"]; ok {
+			acc["roles"] = map[string]interface{}{"roles": listOr(roles)}
+		}
+		if labels, ok := body["selectedPropagationLabels"]; ok {
+			acc["propagationLabels"] = listOr(labels)
+		}
+		writeFakeJSON(w, map[string]interface{}{"id": acc["id"], "name": acc["name"]})
+	case account == "" && len(segments) == 4 && r.Method == "POST" && segments[0] == "host" && segments[1] == "move":
+		acc, ok := f.accounts[strings.TrimPrefix(segments[2], "acc_")]
+		if !ok {
+			writeFakeError(w, http.StatusNotFound, "account not found: "+segments[2])
+			return
+		}
+		acc["hostGroupId"] = segments[3]
+		writeFakeJSON(w, f.listAccounts())
+	case account == "" && route == "GET ha-groups":
+		groups := make([]interface{}, 0, len(f.haGroups))
+		for _, group := range f.haGroups {
+			groups = append(groups, f.haGroup(group))
+		}
+		writeFakeJSON(w, groups)
+	case account == "" && route == "POST ha-group/create":
+		id := stringOr(body["id"], "")
+		if id == "" {
+			id = f.id()
+		}
+		f.haGroups[id] = map[string]interface{}{
+			"id":                   id,
+			"name":                 body["name"],
+			"elasticsearchAddress": body["elasticsearchAddress"],
+			"elasticIndexPrefix":   body["elasticIndexPrefix"],
+		}
+		writeFakeJSON(w, f.haGroup(f.haGroups[id]))
+	case account == "" && len(segments) == 2 && segments[0] == "ha-group":
+		group, ok := f.haGroups[segments[1]]
+		if !ok {
+			writeFakeError(w, http.StatusNotFound, "ha group not found: "+segments[1])
+			return
+		}
+		if r.Method == "DELETE" {
+			delete(f.haGroups, segments[1])
+			writeFakeJSON(w, "deleted")
+			return
+		}
+		writeFakeJSON(w, f.haGroup(group))
+	case account == "" && route == "GET hosts":
+		hosts := make([]interface{}, 0, len(f.hosts))
+		for _, host := range f.hosts {
+			hosts = append(hosts, host)
+		}
+		writeFakeJSON(w, hosts)
+	case account == "" && len(segments) == 2 && r.Method == "DELETE" && segments[0] == "host":
+		if _, ok := f.hosts[segments[1]]; !ok {
+			writeFakeError(w, http.StatusNotFound, "host not found: "+segments[1])
+			return
+		}
+		delete(f.hosts, segments[1])
+		writeFakeJSON(w, "deleted")
+	case account == "" && r.Method == "POST" && len(segments) >= 2 && segments[0] == "host" && segments[1] == "build":
+		writeFakeJSON(w, "installer built")
+	case account == "" && r.Method == "GET" && segments[0] == "host" && len(segments) >= 2 && segments[1] == "download":
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte("#!/bin/sh\nexit 0\n"))
+	case route == "POST classifier":
+		classifiers := f.classifiers[account]
+		id := stringOr(body["id"], "")
+		if id == "" {
+			for _, classifier := range classifiers {
+				if classifier["name"] == body["name"] {
+					writeFakeError(w, http.StatusBadRequest, "classifier already exists: "+stringOr(body["name"], ""))
+					return
+				}
+			}
+			id = f.id()
+		} else if _, ok := classifiers[id]; !ok {
+			writeFakeError(w, http.StatusNotFound, "classifier not found: "+id)
+			return
+		}
+		classifier := map[string]interface{}{
+			"id":                id,
+			"name":              body["name"],
+			"type":              body["type"],
+			"propagationLabels": listOr(body["propagationLabels"]),
+		}
+		if strings.HasPrefix(stringOr(body["type"], ""), "mapping") {
+			classifier["mapping"] = body["keyTypeMap"]
+		} else {
+			classifier["defaultIncidentType"] = body["defaultIncidentType"]
+			classifier["keyTypeMap"] = body["keyTypeMap"]
+			classifier["transformer"] = body["transformer"]
+		}
+		classifiers[id] = classifier
+		writeFakeJSON(w, classifier)
+	case route == "POST classifier/search":
+		classifiers := make([]interface{}, 0, len(f.classifiers[account]))
+		for _, classifier := range f.classifiers[account] {
+			classifiers = append(classifiers, classifier)
+		}
+		writeFakeJSON(w, map[string]interface{}{"classifiers": classifiers})
+	case len(segments) == 2 && r.Method == "DELETE" && segments[0] == "classifier":
+		if _, ok := f.classifiers[account][segments[1]]; !ok {
+			writeFakeError(w, http.StatusNotFound, "classifier not found: "+segments[1])
+			return
+		}
+		delete(f.classifiers[account], segments[1])
+		w.WriteHeader(http.StatusOK)
+	case route == "POST settings/integration/search":
+		instances := make([]interface{}, 0, len(f.instances[account]))
+		for _, instance := range f.instances[account] {
+			instances = append(instances, instance)
+		}
+		writeFakeJSON(w, map[string]interface{}{"configurations": f.integrations, "instances": instances})
+	case route == "PUT settings/integration":
+		instances := f.instances[account]
+		id := stringOr(body["id"], "")
+		if id == "" {
+			for _, instance := range instances {
+				if instance["name"] == body["name"] {
+					writeFakeError(w, http.StatusBadRequest, "integration instance already exists: "+stringOr(body["name"], ""))
+					return
+				}
+			}
+			id = f.id()
+		} else if _, ok := instances[id]; !ok {
+			writeFakeError(w, http.StatusNotFound, "integration instance not found: "+id)
+			return
+		}
+		var data []interface{}
+		for _, parameter := range listOr(body["data"]) {
+			param := parameter.(map[string]interface{})
+			data = append(data, map[string]interface{}{
+				"name":     param["name"],
+				"display":  param["display"],
+				"type":     param["type"],
+				"value":    param["value"],
+				"hasvalue": param["hasvalue"],
+			})
+		}
+		instance := map[string]interface{}{
+			"id":                id,
+			"name":              body["name"],
+			"brand":             body["brand"],
+			"enabled":           stringOr(body["enabled"], "true"),
+			"data":              data,
+			"propagationLabels": listOr(body["propagationLabels"]),
+			"incomingMapperId":  stringOr(body["incomingMapperId"], ""),
+			"mappingId":         stringOr(body["mappingId"], ""),
+			"engine":            stringOr(body["engine"], ""),
+		}
+		instances[id] = instance
+		writeFakeJSON(w, instance)
+	case len(segments) == 3 && r.Method == "DELETE" && segments[0] == "settings" && segments[1] == "integration":
+		if _, ok := f.instances[account][segments[2]]; !ok {
+			writeFakeError(w, http.StatusNotFound, "integration instance not found: "+segments[2])
+			return
+		}
+		delete(f.instances[account], segments[2])
+		w.WriteHeader(http.StatusOK)
+	case route == "GET incidenttype":
+		incidentTypes := make([]interface{}, 0, len(f.incidentTypes[account]))
+		for _, incidentType := range f.incidentTypes[account] {
+			incidentTypes = append(incidentTypes, incidentType)
+		}
+		writeFakeJSON(w, incidentTypes)
+	case route == "POST incidenttype":
+		incidentTypes := f.incidentTypes[account]
+		id := stringOr(body["id"], "")
+		if id == "" {
+			// incident types are identified by their name
+			id = stringOr(body["name"], "")
+			if _, ok := incidentTypes[id]; ok {
+				writeFakeError(w, http.StatusBadRequest, "incident type already exists: "+id)
+				return
+			}
+			body["id"] = id
+			body["version"] = float64(0)
+		} else if _, ok := incidentTypes[id]; !ok {
+			writeFakeError(w, http.StatusNotFound, "incident type not found: "+id)
+			return
+		} else {
+			body["version"] = incidentTypes[id]["version"].(float64) + 1
+		}
+		if _, ok := body["color"]; !ok {
+			body["color"] = "#E2BCFF"
+		}
+		// like the real server, fill in the extraction settings the configuration leaves out for each field
+		extractSettings, _ := body["extractSettings"].(map[string]interface{})
+		fieldSettings, _ := extractSettings["fieldCliNameToExtractSettings"].(map[string]interface{})
+		for _, settings := range fieldSettings {
+			if settings, ok := settings.(map[string]interface{}); ok {
+				for key, value := range map[string]interface{}{
+					"extractAsIsIndicatorTypeId":    "",
+					"isExtractingAllIndicatorTypes": false,
+					"extractIndicatorTypesIDs":      []interface{}{},
+				} {
+					if _, ok := settings[key]; !ok {
+						settings[key] = value
+					}
+				}
+			}
+		}
+		incidentTypes[id] = body
+		writeFakeJSON(w, body)
+	case route == "POST incidenttype/delete":
+		id := stringOr(body["id"], "")
+		if _, ok := f.incidentTypes[account][id]; !ok {
+			writeFakeError(w, http.StatusNotFound, "incident type not found: "+id)
+			return
+		}
+		delete(f.incidentTypes[account], id)
+		w.WriteHeader(http.StatusOK)
+	case route == "GET incidentfields":
+		fields := make([]interface{}, 0, len(f.fields[account]))
+		for _, field := range f.fields[account] {
+			fields = append(fields, field)
+		}
+		writeFakeJSON(w, fields)
+	case route == "POST incidentfield":
+		fields := f.fields[account]
+		id := stringOr(body["id"], "")
+		if id == "" {
+			// fields are identified by their group and machine name, which defaults to the name without spaces
+			cliName := stringOr(body["cliName"], strings.ToLower(strings.ReplaceAll(stringOr(body["name"], ""), " ", "")))
+			prefix := "incident_"
+			if body["group"] == float64(2) {
+				prefix = "indicator_"
+			}
+			id = prefix + cliName
+			if _, ok := fields[id]; ok {
+				writeFakeError(w, http.StatusBadRequest, "field already exists: "+id)
+				return
+			}
+			body["id"] = id
+			body["cliName"] = cliName
+			body["version"] = float64(0)
+		} else if _, ok := fields[id]; !ok {
+			writeFakeError(w, http.StatusNotFound, "field not found: "+id)
+			return
+		} else {
+			body["version"] = fields[id]["version"].(float64) + 1
+		}
+		body["system"] = false
+		// like the real server, fill in the attributes the configuration leaves out of each grid column
+		for _, column := range listOr(body["columns"]) {
+			if column, ok := column.(map[string]interface{}); ok {
+				for key, value := range map[string]interface{}{"isDefault": false, "isReadOnly": false, "required": false, "width": float64(150)} {
+					if _, ok := column[key]; !ok {
+						column[key] = value
+					}
+				}
+			}
+		}
+		fields[id] = body
+		writeFakeJSON(w, body)
+	case len(segments) == 2 && r.Method == "DELETE" && segments[0] == "incidentfield":
+		if _, ok := f.fields[account][segments[1]]; !ok {
+			writeFakeError(w, http.StatusNotFound, "field not found: "+segments[1])
+			return
+		}
+		delete(f.fields[account], segments[1])
+		w.WriteHeader(http.StatusOK)
+	case route == "GET reputations":
+		reputations := make([]interface{}, 0, len(f.reputations[account]))
+		for _, reputation := range f.reputations[account] {
+			reputations = append(reputations, reputation)
+		}
+		writeFakeJSON(w, reputations)
+	case route == "POST reputation":
+		reputations := f.reputations[account]
+		id := stringOr(body["id"], "")
+		if id == "" {
+			// indicator types are identified by their name, which the API calls details
+			id = stringOr(body["details"], "")
+			if _, ok := reputations[id]; ok {
+				writeFakeError(w, http.StatusBadRequest, "indicator type already exists: "+id)
+				return
+			}
+			body["id"] = id
+			body["version"] = float64(0)
+		} else if _, ok := reputations[id]; !ok {
+			writeFakeError(w, http.StatusNotFound, "indicator type not found: "+id)
+			return
+		} else {
+			body["version"] = reputations[id]["version"].(float64) + 1
+		}
+		reputations[id] = body
+		writeFakeJSON(w, body)
+	case len(segments) == 2 && r.Method == "DELETE" && segments[0] == "reputation":
+		if _, ok := f.reputations[account][segments[1]]; !ok {
+			writeFakeError(w, http.StatusNotFound, "indicator type not found: "+segments[1])
+			return
+		}
+		delete(f.reputations[account], segments[1])
+		w.WriteHeader(http.StatusOK)
+	case route == "GET layouts":
+		layouts := make([]interface{}, 0, len(f.layouts[account]))
+		for _, layout := range f.layouts[account] {
+			layouts = append(layouts, layout)
+		}
+		writeFakeJSON(w, layouts)
+	case route == "POST layout/save":
+		layouts := f.layouts[account]
+		id := stringOr(body["id"], "")
+		if id == "" {
+			id = f.id()
+			body["id"] = id
+			body["version"] = float64(0)
+		} else if _, ok := layouts[id]; !ok {
+			writeFakeError(w, http.StatusNotFound, "layout not found: "+id)
+			return
+		} else {
+			body["version"] = layouts[id]["version"].(float64) + 1
+		}
+		if _, ok := body["group"]; !ok {
+			body["group"] = "incident"
+		}
+		// like the real server, give every tab an ID and a hidden flag, which the configuration leaves out
+		for _, key := range []string{"close", "details", "detailsV2", "edit", "indicatorsDetails", "indicatorsQuickView", "mobile", "quickView"} {
+			container, _ := body[key].(map[string]interface{})
+			for _, tab := range listOr(container["tabs"]) {
+				if tab, ok := tab.(map[string]interface{}); ok {
+					if _, ok := tab["id"]; !ok {
+						tab["id"] = f.id()
+					}
+					if _, ok := tab["hidden"]; !ok {
+						tab["hidden"] = false
+					}
+				}
+			}
+		}
+		layouts[id] = body
+		writeFakeJSON(w, body)
+	case len(segments) == 2 && r.Method == "DELETE" && segments[0] == "layout":
+		if _, ok := f.layouts[account][segments[1]]; !ok {
+			writeFakeError(w, http.StatusNotFound, "layout not found: "+segments[1])
+			return
+		}
+		delete(f.layouts[account], segments[1])
+		w.WriteHeader(http.StatusOK)
+	case route == "POST playbook/save/yaml":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeFakeError(w, http.StatusBadRequest, "no playbook file: "+err.Error())
+			return
+		}
+		content, _ := io.ReadAll(file)
+		// the real server parses the whole playbook, but its id and name are all the fake needs
+		id, name := fakeYAMLValue(content, "id"), fakeYAMLValue(content, "name")
+		if id == "" || name == "" {
+			writeFakeError(w, http.StatusBadRequest, "playbook must have an id and a name")
+			return
+		}
+		playbook, ok := f.playbooks[account][id]
+		if !ok {
+			playbook = map[string]interface{}{"id": id, "version": float64(0)}
+			f.playbooks[account][id] = playbook
+		}
+		playbook["name"] = name
+		playbook["version"] = playbook["version"].(float64) + 1
+		f.playbookYAML[account][id] = string(content)
+		writeFakeJSON(w, playbook)
+	case len(segments) == 2 && r.Method == "GET" && segments[0] == "playbook":
+		playbook, ok := f.playbooks[account][segments[1]]
+		if !ok {
+			writeFakeError(w, http.StatusNotFound, "playbook not found: "+segments[1])
+			return
+		}
+		writeFakeJSON(w, playbook)
+	case len(segments) == 3 && r.Method == "GET" && segments[0] == "playbook" && segments[2] == "yaml":
+		content, ok := f.playbookYAML[account][segments[1]]
+		if !ok {
+			writeFakeError(w, http.StatusNotFound, "playbook not found: "+segments[1])
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	case route == "POST playbook/delete":
+		id := stringOr(body["id"], "")
+		if _, ok := f.playbooks[account][id]; !ok {
+			writeFakeError(w, http.StatusNotFound, "playbook not found: "+id)
+			return
+		}
+		delete(f.playbooks[account], id)
+		delete(f.playbookYAML[account], id)
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeFakeError(w, http.StatusNotFound, "no fake handler for "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (f *fakeServer) listAccounts() []interface{} {
+	accounts := make([]interface{}, 0, len(f.accounts))
+	for _, acc := range f.accounts {
+		accounts = append(accounts, acc)
+	}
+	return accounts
+}
+
+func (f *fakeServer) haGroup(group map[string]interface{}) map[string]interface{} {
+	accountIds := []interface{}{}
+	for _, acc := range f.accounts {
+		if acc["hostGroupId"] == group["id"] {
+			accountIds = append(accountIds, acc["id"])
+		}
+	}
+	hostIds := []interface{}{}
+	for _, host := range f.hosts {
+		if host["hostGroupId"] == group["id"] {
+			hostIds = append(hostIds, host["id"])
+		}
+	}
+	result := map[string]interface{}{"accountIds": accountIds, "hostIds": hostIds}
+	for k, v := range group {
+		result[k] = v
+	}
+	return result
+}
+
+func writeFakeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": code, "status": code, "error": message, "detail": message})
+}
+
+func stringOr(v interface{}, fallback string) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fallback
+}
+
+func listOr(v interface{}) []interface{} {
+	if l, ok := v.([]interface{}); ok {
+		return l
+	}
+	return []interface{}{}
+}
+
+// fakeYAMLValue returns the value of a top-level key of a YAML document, without any quotes
+func fakeYAMLValue(content []byte, key string) string {
+	match := regexp.MustCompile(`(?m)^` + key + `:\s*(.*?)\s*$`).FindSubmatch(content)
+	if match == nil {
+		return ""
+	}
+	return strings.Trim(string(match[1]), `"'`)
+}
@@ -0,0 +1,173 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"sort"
+	"strings"
+)
+
+// installerFlags are the flags accepted by the host installer, mapped to whether they take a value.
+var installerFlags = map[string]bool{
+	"y":                   false,
+	"ha":                  false,
+	"multi-tenant":        false,
+	"do-not-start-server": false,
+	"tools":               true,
+	"external-address":    true,
+	"elasticsearch-url":   true,
+	"temp-folder":         true,
+	"http-proxy":          true,
+	"https-proxy":         true,
+	"docker-network":      true,
+	"dockerd-options":     true,
+}
+
+// installerFlag renders a single installer flag, shell-quoting its value if it has one. It returns an error for flags
+// the installer does not know about, or for values given to flags that don't take one.
+func installerFlag(arg string) (string, error) {
+	name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	takesValue, ok := installerFlags[name]
+	if !ok || !strings.HasPrefix(arg, "-") {
+		return "", fmt.Errorf("unknown installer flag %q", arg)
+	}
+	if !hasValue {
+		return "-" + name, nil
+	}
+	if !takesValue {
+		return "", fmt.Errorf("installer flag -%s does not take a value", name)
+	}
+	return "-" + name + "=" + shellQuote(value), nil
+}
+
+type isValidInstallerFlags struct{}
+
+func (v isValidInstallerFlags) Description(ctx context.Context) string {
+	return fmt.Sprintf("each flag must be one of %s", strings.Join(installerFlagNames(), ", "))
+}
+
+func (v isValidInstallerFlags) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("each flag must be one of `%s`", strings.Join(installerFlagNames(), "`, `"))
+}
+
+func (v isValidInstallerFlags) Validate(ctx context.Context, request tfsdk.ValidateAttributeRequest, response *tfsdk.ValidateAttributeResponse) {
+	var flags types.List
+	diags := tfsdk.ValueAs(ctx, request.AttributeConfig, &flags)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() || flags.Null || flags.Unknown {
+		return
+	}
+	for _, elem := range flags.Elems {
+		flag, ok := elem.(types.String)
+		if !ok || flag.Null || flag.Unknown {
+			continue
+		}
+		_, err := installerFlag(flag.Value)
+		if err != nil {
+			response.Diagnostics.AddAttributeError(
+				request.AttributePath,
+				"Invalid Installer Flag",
+				fmt.Sprintf("%s. Flags must be one of %s.", err, strings.Join(installerFlagNames(), ", ")),
+			)
+		}
+	}
+}
+
+func installerFlagNames() []string {
+	var names []string
+	for name := range installerFlags {
+		names = append(names, "-"+name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// installerArgNames returns the flags of installer arguments without their values, which can hold secrets such as
+// credentials in proxy URLs, so the arguments can be logged.
+func installerArgNames(args []string) []string {
+	names := make([]string, 0, len(args))
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		names = append(names, name)
+	}
+	return names
+}
+
+// installerArgs builds the arguments passed to the host installer from the host's attributes, its installer_options
+// block, and any extra_flags. Every value is shell-quoted.
+func installerArgs(ctx context.Context, host Host, isHA bool, isElastic bool) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var options InstallerOptions
+	if len(host.InstallerOptions) > 0 {
+		options = host.InstallerOptions[0]
+	}
+
+	externalAddress := host.Name.Value
+	if !options.ExternalAddress.Null && len(options.ExternalAddress.Value) > 0 {
+		externalAddress = options.ExternalAddress.Value
+	}
+	var args = []string{
+		"-y",
+		"-external-address=" + shellQuote(externalAddress),
+	}
+	if isElastic && !isHA {
+		args = append(args, "-elasticsearch-url="+shellQuote(host.ElasticsearchUrl.Value))
+	}
+	if !options.TempFolder.Null && len(options.TempFolder.Value) > 0 {
+		args = append(args, "-temp-folder="+shellQuote(options.TempFolder.Value))
+	} else if isHA {
+		args = append(args, "-temp-folder="+shellQuote("/tmp/demisto"))
+	}
+	if isHA {
+		args = append(args, "-ha")
+	}
+	for _, option := range []struct {
+		flag  string
+		value types.String
+	}{
+		{"http-proxy", options.HTTPProxy},
+		{"https-proxy", options.HTTPSProxy},
+		{"docker-network", options.DockerNetwork},
+		{"dockerd-options", options.DockerdOptions},
+	} {
+		if !option.value.Null && len(option.value.Value) > 0 {
+			args = append(args, "-"+option.flag+"="+shellQuote(option.value.Value))
+		}
+	}
+	if !options.InstallTools.Null {
+		args = append(args, fmt.Sprintf("-tools=%t", options.InstallTools.Value))
+	}
+	if options.MultiTenant.Value {
+		args = append(args, "-multi-tenant")
+	}
+	if options.DoNotStartServer.Value {
+		args = append(args, "-do-not-start-server")
+	}
+
+	if !host.ExtraFlags.Null {
+		var extraArgs []string
+		flagErr := host.ExtraFlags.ElementsAs(ctx, &extraArgs, false)
+		if flagErr != nil {
+			diags.AddError(
+				"Error extracting extra arguments",
+				fmt.Sprintf("Could not extract %s into extraArgs with error: %s", host.ExtraFlags.Elems, flagErr),
+			)
+			return nil, diags
+		}
+		for _, extraArg := range extraArgs {
+			arg, err := installerFlag(extraArg)
+			if err != nil {
+				diags.AddError(
+					"Invalid installer flag",
+					err.Error(),
+				)
+				return nil, diags
+			}
+			args = append(args, arg)
+		}
+	}
+	return args, diags
+}
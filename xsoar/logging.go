@@ -0,0 +1,199 @@
+package xsoar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxLoggedBodySize is the largest response body written to the log. Only JSON bodies are logged, so installer
+// downloads never are.
+const maxLoggedBodySize = 1 << 20
+
+const redacted = "***"
+
+// secretFields are the JSON keys, lowercased with '_' and '-' removed, whose values are never logged.
+var secretFields = map[string]bool{
+	"apikey":        true,
+	"authorization": true,
+	"credentials":   true,
+	"passphrase":    true,
+	"password":      true,
+	"privatekey":    true,
+	"secret":        true,
+	"sshkey":        true,
+	"token":         true,
+}
+
+// secretHeaders are the request headers whose values are never logged.
+var secretHeaders = map[string]bool{
+	"Authorization":   true,
+	"Cookie":          true,
+	"X-Xdr-Auth-Id":   true,
+	"X-Xdr-Nonce":     true,
+	"X-Xdr-Timestamp": true,
+}
+
+// Integration parameter types whose values are secrets: encrypted strings and credentials.
+const (
+	integrationParamEncrypted   = 4
+	integrationParamCredentials = 9
+)
+
+type logSubsystemKey struct{}
+
+// newLogContext returns a context that logs to the tflog subsystem of a resource or data source. Requests made with
+// the returned context are logged by the provider's HTTP client under that subsystem. The subsystem's level can be
+// set on its own with TF_LOG_PROVIDER_<SUBSYSTEM>.
+func newLogContext(ctx context.Context, subsystem string) context.Context {
+	ctx = tflog.NewSubsystem(ctx, subsystem,
+		tflog.WithLevelFromEnv("TF_LOG_PROVIDER", subsystem),
+		tflog.WithAdditionalLocationOffset(1),
+	)
+	return context.WithValue(ctx, logSubsystemKey{}, subsystem)
+}
+
+// logDebug writes a debug message to the context's subsystem, or to the provider log if it has none.
+func logDebug(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	if subsystem, ok := ctx.Value(logSubsystemKey{}).(string); ok {
+		tflog.SubsystemDebug(ctx, subsystem, msg, fields...)
+		return
+	}
+	tflog.Debug(ctx, msg, fields...)
+}
+
+// logTrace writes a trace message to the context's subsystem, or to the provider log if it has none.
+func logTrace(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	if subsystem, ok := ctx.Value(logSubsystemKey{}).(string); ok {
+		tflog.SubsystemTrace(ctx, subsystem, msg, fields...)
+		return
+	}
+	tflog.Trace(ctx, msg, fields...)
+}
+
+// loggingTransport logs the method, path, status and latency of every request at debug level, and the redacted
+// headers and JSON bodies at trace level. It sits below the retry transport so each attempt is logged.
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	fields := map[string]interface{}{
+		"method": req.Method,
+		"path":   req.URL.Path,
+	}
+
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody && isJSON(req.Header) {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	logTrace(ctx, "Sending request", map[string]interface{}{
+		"method":  req.Method,
+		"path":    req.URL.Path,
+		"headers": redactHeaders(req.Header),
+		"body":    redactBody(reqBody),
+	})
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	fields["latency_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		fields["error"] = err.Error()
+		logDebug(ctx, "Request failed", fields)
+		return resp, err
+	}
+	fields["status"] = resp.StatusCode
+	logDebug(ctx, "Received response", fields)
+
+	if isJSON(resp.Header) {
+		respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBodySize+1))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(respBody), resp.Body), resp.Body}
+		if err != nil {
+			return resp, nil
+		}
+		if len(respBody) > maxLoggedBodySize {
+			respBody = nil
+		}
+		logTrace(ctx, "Response body", map[string]interface{}{
+			"method": req.Method,
+			"path":   req.URL.Path,
+			"body":   redactBody(respBody),
+		})
+	}
+	return resp, nil
+}
+
+func isJSON(header http.Header) bool {
+	return strings.Contains(header.Get("Content-Type"), "json")
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	headers := map[string]string{}
+	for name, values := range header {
+		if secretHeaders[http.CanonicalHeaderKey(name)] {
+			headers[name] = redacted
+		} else {
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+	return headers
+}
+
+// redactBody returns a JSON body with the values of secret fields and secret integration parameters replaced. Bodies
+// that are not valid JSON are not logged.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "(non-JSON body omitted)"
+	}
+	b, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if paramType, ok := v["type"].(float64); ok && (paramType == integrationParamEncrypted || paramType == integrationParamCredentials) {
+			for _, key := range []string{"value", "defaultValue"} {
+				if _, ok := v[key]; ok {
+					v[key] = redacted
+				}
+			}
+		}
+		for key, elem := range v {
+			if secretFields[strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))] {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(elem)
+		}
+		return v
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = redactValue(elem)
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,55 @@
+package xsoar
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", ``, ``},
+		{"not json", `<html>502 Bad Gateway</html>`, `(non-JSON body omitted)`},
+		{"no secrets", `{"name":"foo","id":"1"}`, `{"id":"1","name":"foo"}`},
+		{"password", `{"name":"foo","password":"hunter2"}`, `{"name":"foo","password":"***"}`},
+		{"key spelled differently", `{"API_Key":"k","ssh-key":"k","privateKey":"k"}`, `{"API_Key":"***","privateKey":"***","ssh-key":"***"}`},
+		{"secret object", `{"credentials":{"identifier":"admin","password":"hunter2"}}`, `{"credentials":"***"}`},
+		{"nested", `{"data":[{"token":"t","user":"u"}]}`, `{"data":[{"token":"***","user":"u"}]}`},
+		{"encrypted parameter", `{"data":[{"name":"APIKey","type":4,"value":"k","defaultValue":"d"}]}`, `{"data":[{"defaultValue":"***","name":"APIKey","type":4,"value":"***"}]}`},
+		{"credentials parameter", `{"name":"login","type":9,"value":{"identifier":"admin"}}`, `{"name":"login","type":9,"value":"***"}`},
+		{"plain parameter", `{"name":"url","type":0,"value":"https://example.com"}`, `{"name":"url","type":0,"value":"https://example.com"}`},
+		{"top level list", `[{"secret":"s"},"password"]`, `[{"secret":"***"},"password"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactBody([]byte(tt.body)); got != tt.want {
+				t.Errorf("redactBody(%s) = %s, want %s", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   map[string]string
+	}{
+		{"none", http.Header{}, map[string]string{}},
+		{"api key", http.Header{"Authorization": {"key"}, "Accept": {"application/json"}}, map[string]string{"Authorization": "***", "Accept": "application/json"}},
+		{"advanced auth", http.Header{"X-Xdr-Auth-Id": {"7"}, "X-Xdr-Nonce": {"n"}, "X-Xdr-Timestamp": {"1"}}, map[string]string{"X-Xdr-Auth-Id": "***", "X-Xdr-Nonce": "***", "X-Xdr-Timestamp": "***"}},
+		{"cookie", http.Header{"Cookie": {"a=1", "b=2"}}, map[string]string{"Cookie": "***"}},
+		{"repeated", http.Header{"Accept": {"application/json", "*/*"}}, map[string]string{"Accept": "application/json, */*"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactHeaders(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redactHeaders(%v) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ type Account struct {
 	PropagationLabels types.Set    `tfsdk:"propagation_labels"`
 	Timeout           types.Int64  `tfsdk:"timeout"`
 	Concurrency       types.Int64  `tfsdk:"concurrency_limit"`
+	Timeouts          []Timeouts   `tfsdk:"timeouts"`
 }
 
 // Accounts -
@@ -29,6 +30,7 @@ type HAGroup struct {
 	ElasticIndexPrefix types.String `tfsdk:"elastic_index_prefix"`
 	AccountIds         types.Set    `tfsdk:"account_ids"`
 	HostIds            types.Set    `tfsdk:"host_ids"`
+	Timeouts           []Timeouts   `tfsdk:"timeouts"`
 }
 
 // HAGroups -
@@ -38,18 +40,85 @@ type HAGroups struct {
 	Groups      types.Set    `tfsdk:"groups"`
 }
 
+// ServerInfo -
+type ServerInfo struct {
+	Id           types.String `tfsdk:"id"`
+	Version      types.String `tfsdk:"version"`
+	BuildNumber  types.String `tfsdk:"build_number"`
+	MajorVersion types.Int64  `tfsdk:"major_version"`
+	MinorVersion types.Int64  `tfsdk:"minor_version"`
+	Capabilities types.Set    `tfsdk:"capabilities"`
+}
+
 // Host -
 type Host struct {
-	Name                types.String `tfsdk:"name"`
-	Id                  types.String `tfsdk:"id"`
-	HAGroupName         types.String `tfsdk:"ha_group_name"`
-	NFSMount            types.String `tfsdk:"nfs_mount"`
-	ElasticsearchUrl    types.String `tfsdk:"elasticsearch_url"`
-	ServerUrl           types.String `tfsdk:"server_url"`
-	SSHUser             types.String `tfsdk:"ssh_user"`
-	SSHKey              types.String `tfsdk:"ssh_key"`
-	InstallationTimeout types.Int64  `tfsdk:"installation_timeout"`
-	ExtraFlags          types.List   `tfsdk:"extra_flags"`
+	Name                types.String       `tfsdk:"name"`
+	Id                  types.String       `tfsdk:"id"`
+	HAGroupName         types.String       `tfsdk:"ha_group_name"`
+	NFSMount            types.String       `tfsdk:"nfs_mount"`
+	ElasticsearchUrl    types.String       `tfsdk:"elasticsearch_url"`
+	ServerUrl           types.String       `tfsdk:"server_url"`
+	SSHUser             types.String       `tfsdk:"ssh_user"`
+	SSHKey              types.String       `tfsdk:"ssh_key"`
+	SSHKeyPassphrase    types.String       `tfsdk:"ssh_key_passphrase"`
+	SSHCertificate      types.String       `tfsdk:"ssh_certificate"`
+	SSHPassword         types.String       `tfsdk:"ssh_password"`
+	SSHAgent            types.Bool         `tfsdk:"ssh_agent"`
+	HostKey             types.String       `tfsdk:"host_key"`
+	KnownHosts          types.String       `tfsdk:"known_hosts"`
+	KnownHostsFile      types.String       `tfsdk:"known_hosts_file"`
+	InstalledVersion    types.String       `tfsdk:"installed_version"`
+	ServerVersion       types.String       `tfsdk:"server_version"`
+	ForceDestroy        types.Bool         `tfsdk:"force_destroy"`
+	DrainToHostGroup    types.String       `tfsdk:"drain_to_host_group"`
+	InstallationTimeout types.Int64        `tfsdk:"installation_timeout"`
+	ExtraFlags          types.List         `tfsdk:"extra_flags"`
+	InstallerOptions    []InstallerOptions `tfsdk:"installer_options"`
+	Bastion             []Bastion          `tfsdk:"bastion"`
+	Timeouts            []Timeouts         `tfsdk:"timeouts"`
+}
+
+// HostDataSource - only what the data source reads, as the SSH settings of Host are only used to install hosts
+type HostDataSource struct {
+	Name             types.String `tfsdk:"name"`
+	Id               types.String `tfsdk:"id"`
+	HAGroupName      types.String `tfsdk:"ha_group_name"`
+	ElasticsearchUrl types.String `tfsdk:"elasticsearch_url"`
+	ServerUrl        types.String `tfsdk:"server_url"`
+	SSHUser          types.String `tfsdk:"ssh_user"`
+	SSHKey           types.String `tfsdk:"ssh_key"`
+	InstalledVersion types.String `tfsdk:"installed_version"`
+	Timeouts         []Timeouts   `tfsdk:"timeouts"`
+}
+
+// Timeouts -
+type Timeouts struct {
+	Create types.String `tfsdk:"create"`
+	Read   types.String `tfsdk:"read"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+// InstallerOptions -
+type InstallerOptions struct {
+	ExternalAddress  types.String `tfsdk:"external_address"`
+	TempFolder       types.String `tfsdk:"temp_folder"`
+	HTTPProxy        types.String `tfsdk:"http_proxy"`
+	HTTPSProxy       types.String `tfsdk:"https_proxy"`
+	DockerNetwork    types.String `tfsdk:"docker_network"`
+	DockerdOptions   types.String `tfsdk:"dockerd_options"`
+	InstallTools     types.Bool   `tfsdk:"install_tools"`
+	MultiTenant      types.Bool   `tfsdk:"multi_tenant"`
+	DoNotStartServer types.Bool   `tfsdk:"do_not_start_server"`
+}
+
+// Bastion -
+type Bastion struct {
+	Address          types.String `tfsdk:"address"`
+	SSHUser          types.String `tfsdk:"ssh_user"`
+	SSHKey           types.String `tfsdk:"ssh_key"`
+	SSHKeyPassphrase types.String `tfsdk:"ssh_key_passphrase"`
+	HostKey          types.String `tfsdk:"host_key"`
 }
 
 // IntegrationInstance -
@@ -65,6 +134,7 @@ type IntegrationInstance struct {
 	IncomingMapperId  types.String `tfsdk:"incoming_mapper_id"`
 	MappingId         types.String `tfsdk:"mapping_id"`
 	EngineId          types.String `tfsdk:"engine_id"`
+	Timeouts          []Timeouts   `tfsdk:"timeouts"`
 }
 
 // Classifier -
@@ -76,6 +146,7 @@ type Classifier struct {
 	Transformer         types.String `tfsdk:"transformer"`
 	PropagationLabels   types.Set    `tfsdk:"propagation_labels"`
 	Account             types.String `tfsdk:"account"`
+	Timeouts            []Timeouts   `tfsdk:"timeouts"`
 }
 
 // Mapper -
@@ -86,4 +157,79 @@ type Mapper struct {
 	PropagationLabels types.Set    `tfsdk:"propagation_labels"`
 	Account           types.String `tfsdk:"account"`
 	Direction         types.String `tfsdk:"direction"`
+	Timeouts          []Timeouts   `tfsdk:"timeouts"`
+}
+
+// IncidentType -
+type IncidentType struct {
+	Name              types.String `tfsdk:"name"`
+	Id                types.String `tfsdk:"id"`
+	Color             types.String `tfsdk:"color"`
+	Playbook          types.String `tfsdk:"playbook"`
+	Autorun           types.Bool   `tfsdk:"autorun"`
+	Layout            types.String `tfsdk:"layout"`
+	Sla               types.Int64  `tfsdk:"sla"`
+	SlaReminder       types.Int64  `tfsdk:"sla_reminder"`
+	AutoExtractMode   types.String `tfsdk:"auto_extract_mode"`
+	AutoExtractFields types.String `tfsdk:"auto_extract_fields"`
+	PropagationLabels types.Set    `tfsdk:"propagation_labels"`
+	Account           types.String `tfsdk:"account"`
+	Timeouts          []Timeouts   `tfsdk:"timeouts"`
+}
+
+// IncidentField - also the model of indicator fields
+type IncidentField struct {
+	Name              types.String `tfsdk:"name"`
+	Id                types.String `tfsdk:"id"`
+	CliName           types.String `tfsdk:"cli_name"`
+	Type              types.String `tfsdk:"type"`
+	Description       types.String `tfsdk:"description"`
+	SelectValues      types.List   `tfsdk:"select_values"`
+	GridColumns       types.String `tfsdk:"grid_columns"`
+	AssociatedTypes   types.Set    `tfsdk:"associated_types"`
+	AssociatedToAll   types.Bool   `tfsdk:"associated_to_all"`
+	Required          types.Bool   `tfsdk:"required"`
+	System            types.Bool   `tfsdk:"system"`
+	PropagationLabels types.Set    `tfsdk:"propagation_labels"`
+	Account           types.String `tfsdk:"account"`
+	Timeouts          []Timeouts   `tfsdk:"timeouts"`
+}
+
+// IndicatorType -
+type IndicatorType struct {
+	Name              types.String `tfsdk:"name"`
+	Id                types.String `tfsdk:"id"`
+	Regex             types.String `tfsdk:"regex"`
+	ReputationCommand types.String `tfsdk:"reputation_command"`
+	ReputationScript  types.String `tfsdk:"reputation_script"`
+	FormatScript      types.String `tfsdk:"format_script"`
+	Layout            types.String `tfsdk:"layout"`
+	Expiration        types.Int64  `tfsdk:"expiration"`
+	PropagationLabels types.Set    `tfsdk:"propagation_labels"`
+	Account           types.String `tfsdk:"account"`
+	Timeouts          []Timeouts   `tfsdk:"timeouts"`
+}
+
+// Layout -
+type Layout struct {
+	Name              types.String `tfsdk:"name"`
+	Id                types.String `tfsdk:"id"`
+	Group             types.String `tfsdk:"group"`
+	Description       types.String `tfsdk:"description"`
+	Layout            types.String `tfsdk:"layout"`
+	PropagationLabels types.Set    `tfsdk:"propagation_labels"`
+	Account           types.String `tfsdk:"account"`
+	Timeouts          []Timeouts   `tfsdk:"timeouts"`
+}
+
+// Playbook -
+type Playbook struct {
+	Name     types.String `tfsdk:"name"`
+	Id       types.String `tfsdk:"id"`
+	Version  types.Int64  `tfsdk:"version"`
+	Content  types.String `tfsdk:"content"`
+	File     types.String `tfsdk:"file"`
+	FileHash types.String `tfsdk:"file_hash"`
+	Account  types.String `tfsdk:"account"`
+	Timeouts []Timeouts   `tfsdk:"timeouts"`
 }
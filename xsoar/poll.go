@@ -0,0 +1,52 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"math/rand"
+	"time"
+)
+
+const (
+	// pollBackoff is the factor the wait between attempts grows by after each retry
+	pollBackoff = 2
+	// pollJitter is the fraction each wait is randomly lengthened or shortened by, so that resources waiting on the
+	// same thing don't all call the API at once
+	pollJitter = 0.2
+)
+
+// poll calls f until it succeeds or returns a non-retryable error, waiting interval between attempts and doubling the
+// wait after each retry up to maxInterval. It stops as soon as the context is done, returning the context's error
+// together with the last reason f gave for retrying.
+func poll(ctx context.Context, interval, maxInterval time.Duration, f func() *resource.RetryError) error {
+	for attempt := 1; ; attempt++ {
+		retryErr := f()
+		if retryErr == nil {
+			return nil
+		}
+		if !retryErr.Retryable {
+			return retryErr.Err
+		}
+
+		wait := jitter(interval)
+		logDebug(ctx, "Polling", map[string]interface{}{
+			"attempt": attempt,
+			"reason":  retryErr.Err.Error(),
+			"wait_ms": wait.Milliseconds(),
+		})
+		if err := sleep(ctx, wait); err != nil {
+			return fmt.Errorf("%s: %w", err, retryErr.Err)
+		}
+
+		interval *= pollBackoff
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// jitter randomly lengthens or shortens d by up to pollJitter of it
+func jitter(d time.Duration) time.Duration {
+	return time.Duration(float64(d) * (1 + pollJitter*(2*rand.Float64()-1)))
+}
@@ -2,13 +2,15 @@ package xsoar
 
 import (
 	"context"
-	"crypto/tls"
 	"github.com/badarsebard/xsoar-sdk-go/openapi"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"net/http"
 	"os"
+	"strings"
 )
 
 var _ = os.Stderr
@@ -36,15 +38,84 @@ func (p *provider) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics)
 				Type:     types.StringType,
 				Optional: true,
 			},
+			"api_key_id": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"auth_mode": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"default_account": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"tenancy": {
+				Type:     types.StringType,
+				Optional: true,
+			},
 			"insecure": {
 				Type:     types.BoolType,
 				Optional: true,
 			},
+			"ca_cert_file": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"ca_cert_pem": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"client_cert": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"client_key": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"tls_server_name": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"proxy_url": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"no_proxy": {
+				Type:     types.StringType,
+				Optional: true,
+			},
 			"http_headers_from_env": {
 				Type:     types.MapType{ElemType: types.StringType},
 				Optional: true,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"retry": {
+				NestingMode: tfsdk.BlockNestingModeList,
+				MaxItems:    1,
+				Attributes: map[string]tfsdk.Attribute{
+					"max_attempts": {
+						Type:     types.Int64Type,
+						Optional: true,
+					},
+					"min_backoff": {
+						Type:     types.Int64Type,
+						Optional: true,
+					},
+					"max_backoff": {
+						Type:     types.Int64Type,
+						Optional: true,
+					},
+					"retryable_status_codes": {
+						Type:     types.ListType{ElemType: types.Int64Type},
+						Optional: true,
+					},
+				},
+			},
+		},
 	}, nil
 }
 
@@ -52,8 +123,28 @@ func (p *provider) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics)
 type providerData struct {
 	Apikey             types.String      `tfsdk:"api_key"`
 	MainHost           types.String      `tfsdk:"main_host"`
+	ApiKeyId           types.String      `tfsdk:"api_key_id"`
+	AuthMode           types.String      `tfsdk:"auth_mode"`
+	Tenancy            types.String      `tfsdk:"tenancy"`
+	DefaultAccount     types.String      `tfsdk:"default_account"`
 	Insecure           types.Bool        `tfsdk:"insecure"`
+	CACertFile         types.String      `tfsdk:"ca_cert_file"`
+	CACertPEM          types.String      `tfsdk:"ca_cert_pem"`
+	ClientCert         types.String      `tfsdk:"client_cert"`
+	ClientKey          types.String      `tfsdk:"client_key"`
+	TLSServerName      types.String      `tfsdk:"tls_server_name"`
+	ProxyUrl           types.String      `tfsdk:"proxy_url"`
+	NoProxy            types.String      `tfsdk:"no_proxy"`
 	HttpHeadersFromEnv map[string]string `tfsdk:"http_headers_from_env"`
+	Retry              []providerRetry   `tfsdk:"retry"`
+	Server             *serverInfo       `tfsdk:"-"`
+}
+
+type providerRetry struct {
+	MaxAttempts types.Int64 `tfsdk:"max_attempts"`
+	MinBackoff  types.Int64 `tfsdk:"min_backoff"`
+	MaxBackoff  types.Int64 `tfsdk:"max_backoff"`
+	StatusCodes []int64     `tfsdk:"retryable_status_codes"`
 }
 
 func (p *provider) Configure(ctx context.Context, req tfsdk.ConfigureProviderRequest, resp *tfsdk.ConfigureProviderResponse) {
@@ -130,30 +221,252 @@ func (p *provider) Configure(ctx context.Context, req tfsdk.ConfigureProviderReq
 	}
 	insecure = config.Insecure.Value
 
+	// XSOAR 8 and XSIAM identify the API key by its ID, and advanced keys sign every request
+	if config.ApiKeyId.Null && len(os.Getenv("DEMISTO_API_KEY_ID")) > 0 {
+		config.ApiKeyId.Value = os.Getenv("DEMISTO_API_KEY_ID")
+		config.ApiKeyId.Null = false
+	}
+	if config.AuthMode.Null {
+		config.AuthMode.Value = os.Getenv("DEMISTO_AUTH_MODE")
+		if config.AuthMode.Value == "" {
+			config.AuthMode.Value = "standard"
+		}
+		config.AuthMode.Null = false
+	}
+	if config.AuthMode.Value != "standard" && config.AuthMode.Value != "advanced" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("auth_mode"),
+			"Invalid auth mode",
+			"auth_mode must be either standard or advanced, got: "+config.AuthMode.Value,
+		)
+		return
+	}
+	advanced := config.AuthMode.Value == "advanced"
+	if advanced && (config.ApiKeyId.Null || config.ApiKeyId.Value == "") {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_key_id"),
+			"Unable to find API key ID",
+			"api_key_id is required when auth_mode is advanced",
+		)
+		return
+	}
+
 	// Create a new xsoar client and set it to the provider client
 	openapiConfig := openapi.NewConfiguration()
 	openapiConfig.Servers[0].URL = mainhost
-	openapiConfig.AddDefaultHeader("Authorization", apikey)
+	if !advanced {
+		openapiConfig.AddDefaultHeader("Authorization", apikey)
+	}
+	if !config.ApiKeyId.Null && config.ApiKeyId.Value != "" {
+		openapiConfig.AddDefaultHeader("x-xdr-auth-id", config.ApiKeyId.Value)
+	}
 	openapiConfig.AddDefaultHeader("Accept", "application/json,*/*")
 	if config.HttpHeadersFromEnv != nil {
 		for key, value := range config.HttpHeadersFromEnv {
 			openapiConfig.AddDefaultHeader(key, os.Getenv(value))
 		}
 	}
-	if insecure {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	for _, setting := range []struct {
+		value *types.String
+		env   string
+	}{
+		{&config.CACertFile, "DEMISTO_CA_CERT_FILE"},
+		{&config.CACertPEM, "DEMISTO_CA_CERT_PEM"},
+		{&config.ClientCert, "DEMISTO_CLIENT_CERT"},
+		{&config.ClientKey, "DEMISTO_CLIENT_KEY"},
+		{&config.TLSServerName, "DEMISTO_TLS_SERVER_NAME"},
+		{&config.ProxyUrl, "DEMISTO_PROXY_URL"},
+		{&config.NoProxy, "DEMISTO_NO_PROXY"},
+	} {
+		if setting.value.Null && len(os.Getenv(setting.env)) > 0 {
+			setting.value.Value = os.Getenv(setting.env)
+			setting.value.Null = false
 		}
-		client := &http.Client{Transport: tr}
-		openapiConfig.HTTPClient = client
 	}
+	tlsConfig, diags := newTLSConfig(config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tlsConfig.InsecureSkipVerify = insecure
+	proxy, diags := newProxyFunc(config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = tlsConfig
+	tr.Proxy = proxy
+
+	// Retry requests while the main host is busy
+	var retry providerRetry
+	if len(config.Retry) > 0 {
+		retry = config.Retry[0]
+	}
+	if !retry.MaxAttempts.Null && retry.MaxAttempts.Value < 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry").AtListIndex(0).AtName("max_attempts"),
+			"Invalid retry settings",
+			"max_attempts must be at least 1",
+		)
+		return
+	}
+	if !retry.MinBackoff.Null && !retry.MaxBackoff.Null && retry.MinBackoff.Value > retry.MaxBackoff.Value {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry").AtListIndex(0).AtName("min_backoff"),
+			"Invalid retry settings",
+			"min_backoff cannot be greater than max_backoff",
+		)
+		return
+	}
+	var base http.RoundTripper = tr
+	if advanced {
+		base = &advancedAuthTransport{base: tr, apiKey: apikey}
+	}
+	client := &http.Client{Transport: newRetryTransport(&loggingTransport{base: base}, retry)}
+	openapiConfig.HTTPClient = client
 	c := openapi.NewAPIClient(openapiConfig)
 
+	// Look up what server we're talking to once, so resources can adjust to it. This is only a hint, so it is not
+	// retried: while the server is down every plan would otherwise wait out the whole retry budget here first.
+	server, err := getServerInfo(withoutRetries(ctx), c)
+	if err != nil {
+		tflog.Warn(ctx, "Could not get server info", map[string]interface{}{"error": err.Error()})
+	} else {
+		tflog.Info(ctx, "Connected to XSOAR", map[string]interface{}{"version": server.Version, "build_number": server.BuildNumber})
+		config.Server = server
+	}
+
+	// Multi-tenant by default, unless the server can't be one
+	if config.Tenancy.Null {
+		config.Tenancy.Value = os.Getenv("DEMISTO_TENANCY")
+		if config.Tenancy.Value == "" {
+			if config.Server.supports(capabilityMultiTenantHosts) {
+				config.Tenancy.Value = "multi"
+			} else {
+				config.Tenancy.Value = "single"
+			}
+		}
+		config.Tenancy.Null = false
+	}
+	if config.Tenancy.Value != "multi" && config.Tenancy.Value != "single" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("tenancy"),
+			"Invalid tenancy",
+			"tenancy must be either multi or single, got: "+config.Tenancy.Value,
+		)
+		return
+	}
+
+	// Account-scoped resources without an account of their own belong to the default account
+	if config.DefaultAccount.Null {
+		config.DefaultAccount.Value = os.Getenv("DEMISTO_ACCOUNT")
+		config.DefaultAccount.Null = config.DefaultAccount.Value == ""
+	}
+	if !config.DefaultAccount.Null && config.Tenancy.Value == "single" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("default_account"),
+			"Unsupported account",
+			"default_account can only be set on a multi-tenant main host, but the provider tenancy is single.",
+		)
+		return
+	}
+
 	p.client = c
 	p.configured = true
 	p.data = &config
 }
 
+// singleTenant reports whether the provider is managing a single-tenant server
+func (p provider) singleTenant() bool {
+	return p.data != nil && p.data.Tenancy.Value == "single"
+}
+
+// accountName returns the name requests for an account-scoped object are routed to, falling back to the default
+// account, and false if they should go to the main host itself instead. That is always the case on a single-tenant
+// server.
+func (p provider) accountName(account types.String) (string, bool) {
+	if p.singleTenant() || account.Unknown {
+		return "", false
+	}
+	if (account.Null || len(account.Value) == 0) && p.data != nil {
+		account = p.data.DefaultAccount
+	}
+	if account.Null || len(account.Value) == 0 {
+		return "", false
+	}
+	return "acc_" + account.Value, true
+}
+
+// accountPrefix returns the path prefix, "/acc_<name>", of the endpoints of the account a resource belongs to, or "" if
+// it belongs to the main tenant. It is used for the endpoints the SDK has no account-scoped variant of.
+func (p provider) accountPrefix(account types.String) string {
+	if accName, ok := p.accountName(account); ok {
+		return "/" + accName
+	}
+	return ""
+}
+
+// splitImportId splits the import ID of an account-scoped resource, "account.name" or just "name", into its parts.
+// IDs without an account belong to the default account if there is one.
+func (p provider) splitImportId(id string) []string {
+	accname := strings.Split(id, ".")
+	if len(accname) == 1 && !p.singleTenant() && p.data != nil && !p.data.DefaultAccount.Null {
+		accname = []string{p.data.DefaultAccount.Value, id}
+	}
+	return accname
+}
+
+// validateMultiTenant returns an error for resources that only exist on a multi-tenant main host when the provider is
+// managing a single-tenant server
+func (p provider) validateMultiTenant(resourceType string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if p.singleTenant() {
+		diags.AddError(
+			"Unsupported resource",
+			resourceType+" can only be managed on a multi-tenant main host, but the provider tenancy is single.",
+		)
+	}
+	return diags
+}
+
+// planAccount plans the account of an account-scoped resource. An account can only be given when the provider is
+// managing a multi-tenant main host, and the provider's default account is used when the resource doesn't set one.
+func (p provider) planAccount(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	var account types.String
+	diags := req.Config.GetAttribute(ctx, path.Root("account"), &account)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !account.Null {
+		if p.singleTenant() && !account.Unknown && len(account.Value) > 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("account"),
+				"Unsupported account",
+				"account can only be set on a multi-tenant main host, but the provider tenancy is single.",
+			)
+		}
+		return
+	}
+
+	account = types.String{Null: true}
+	if p.data != nil && !p.data.DefaultAccount.Null {
+		account = p.data.DefaultAccount
+	}
+	diags = resp.Plan.SetAttribute(ctx, path.Root("account"), account)
+	resp.Diagnostics.Append(diags...)
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var stateAccount types.String
+	diags = req.State.GetAttribute(ctx, path.Root("account"), &stateAccount)
+	resp.Diagnostics.Append(diags...)
+	if !stateAccount.Equal(account) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("account"))
+	}
+}
+
 // GetResources - Defines provider resources
 func (p *provider) GetResources(_ context.Context) (map[string]tfsdk.ResourceType, diag.Diagnostics) {
 	return map[string]tfsdk.ResourceType{
@@ -163,6 +476,12 @@ func (p *provider) GetResources(_ context.Context) (map[string]tfsdk.ResourceTyp
 		"xsoar_integration_instance": resourceIntegrationInstanceType{},
 		"xsoar_classifier":           resourceClassifierType{},
 		"xsoar_mapper":               resourceMapperType{},
+		"xsoar_incident_type":        resourceIncidentTypeType{},
+		"xsoar_incident_field":       resourceIncidentFieldType{},
+		"xsoar_indicator_type":       resourceIndicatorTypeType{},
+		"xsoar_indicator_field":      resourceIndicatorFieldType{},
+		"xsoar_layout":               resourceLayoutType{},
+		"xsoar_playbook":             resourcePlaybookType{},
 	}, nil
 }
 
@@ -177,5 +496,6 @@ func (p *provider) GetDataSources(_ context.Context) (map[string]tfsdk.DataSourc
 		"xsoar_integration_instance": dataSourceIntegrationInstanceType{},
 		"xsoar_classifier":           dataSourceClassifierType{},
 		"xsoar_mapper":               dataSourceMapperType{},
+		"xsoar_server_info":          dataSourceServerInfoType{},
 	}, nil
 }
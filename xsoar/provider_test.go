@@ -10,6 +10,14 @@ import (
 var openapiClient *openapi.APIClient
 
 func init() {
+	// Without a live server configured, run the acceptance tests against the in-process fake. The provider reads the
+	// same environment variables, so both the provider under test and openapiClient are pointed at it.
+	if os.Getenv("DEMISTO_BASE_URL") == "" {
+		fake := newFakeServer()
+		os.Setenv("DEMISTO_BASE_URL", fake.URL)
+		os.Setenv("DEMISTO_API_KEY", fakeAPIKey)
+	}
+
 	apikey := os.Getenv("DEMISTO_API_KEY")
 	mainhost := os.Getenv("DEMISTO_BASE_URL")
 	openapiConfig := openapi.NewConfiguration()
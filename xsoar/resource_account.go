@@ -6,14 +6,12 @@ import (
 	"github.com/badarsebard/xsoar-sdk-go/openapi"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"hash/crc64"
-	"io"
-	"log"
 	"math/rand"
-	"net/http"
 	"time"
 )
 
@@ -57,14 +55,18 @@ func (r resourceAccountType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Di
 				Computed: true,
 			},
 			"timeout": {
-				Type:     types.Int64Type,
-				Optional: true,
+				Type:               types.Int64Type,
+				Optional:           true,
+				DeprecationMessage: "Use the create timeout of the timeouts block instead.",
 			},
 			"concurrency_limit": {
 				Type:     types.Int64Type,
 				Optional: true,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -81,6 +83,7 @@ type resourceAccount struct {
 
 // Create a new resource
 func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_account")
 	if !r.p.configured {
 		resp.Diagnostics.AddError(
 			"Provider not configured",
@@ -88,24 +91,33 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		)
 		return
 	}
+	if !r.p.data.Server.supports(capabilityMultiTenantHosts) {
+		resp.Diagnostics.AddError(
+			"Unsupported server version",
+			"Accounts are only supported on multi-tenant XSOAR 6 main hosts, but the server is running XSOAR "+r.p.data.Server.Version+".",
+		)
+		return
+	}
 
 	// Retrieve values from plan
 	var plan Account
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
-		log.Printf("%+v\n", req.Plan)
 		return
 	}
+	timeout := 30 * time.Minute
+	if !plan.Timeout.Null && plan.Timeout.Value > 0 {
+		timeout = time.Duration(plan.Timeout.Value) * time.Second
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, timeout)
+	defer cancel()
 
 	// Generate API request body from plan
 	createAccountRequest := *openapi.NewCreateAccountRequest()
 	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not list HA groups: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not list HA groups", err)
 		return
 	}
 	var hostGroupId = ""
@@ -132,31 +144,27 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 	createAccountRequest.SetSyncOnCreation(true)
 
 	// Create new account
-	var accounts []map[string]interface{}
-	timeout := time.Duration(1800) * time.Second
-	if !plan.Timeout.Null && plan.Timeout.Value > 0 {
-		timeout = time.Duration(plan.Timeout.Value) * time.Second
+	// initial random delay
+	crcTable := crc64.MakeTable(crc64.ISO)
+	seedInt := int64(crc64.Checksum([]byte(plan.Name.Value), crcTable))
+	randSource := rand.NewSource(seedInt)
+	nrand := rand.New(randSource)
+	randomTimeToWait := nrand.Intn(90) + 1
+	logDebug(ctx, "Delaying account creation", map[string]interface{}{"seconds": randomTimeToWait})
+	err = sleep(ctx, time.Duration(randomTimeToWait)*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating account",
+			"Could not create account: "+err.Error(),
+		)
+		return
 	}
-	err = resource.RetryContext(ctx, timeout, func() *resource.RetryError {
-		var httpResponse *http.Response
-		var body []byte
-		// initial random delay
-		crcTable := crc64.MakeTable(crc64.ISO)
-		seedInt := int64(crc64.Checksum([]byte(plan.Name.Value), crcTable))
-		log.Printf("generated seed: %d\n", seedInt)
-		randSource := rand.NewSource(seedInt)
-		nrand := rand.New(randSource)
-		randomTimeToWait := nrand.Intn(90) + 1
-		log.Printf("sleeping for %d seconds\n", randomTimeToWait)
-		time.Sleep(time.Duration(randomTimeToWait) * time.Second)
+	var accounts []map[string]interface{}
+	err = poll(ctx, 15*time.Second, time.Minute, func() *resource.RetryError {
 		// wait until no other accounts are being created
-		accounts, httpResponse, err = r.p.client.DefaultApi.ListAccounts(ctx).Execute()
-		if httpResponse != nil {
-			body, _ = io.ReadAll(httpResponse.Body)
-			log.Printf("%s : %s\n", httpResponse.Status, body)
-		}
+		accounts, _, err = r.p.client.DefaultApi.ListAccounts(ctx).Execute()
 		if err != nil {
-			return resource.RetryableError(fmt.Errorf("error message: %s, http response: %s", err, body))
+			return resource.RetryableError(fmt.Errorf("error message: %w", err))
 		}
 		var accountsBeingCreated int64 = 0
 		var concurrencyLimit int64 = 1
@@ -168,53 +176,42 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 				concurrencyLimit = plan.Concurrency.Value
 			}
 			if accountsBeingCreated >= concurrencyLimit {
-				time.Sleep(60 * time.Second)
 				return resource.RetryableError(fmt.Errorf("waiting for account %s to finish creation", account["name"].(string)))
 			}
 		}
 		// Create account
-		log.Printf("creating account")
+		logDebug(ctx, "Creating account")
 
-		_, httpResponse, err = r.p.client.DefaultApi.CreateAccount(ctx).CreateAccountRequest(createAccountRequest).Execute()
-		if httpResponse != nil {
-			body, _ = io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("%s : %s - %s\n", payload, httpResponse.Status, body)
-		}
+		_, _, err = r.p.client.DefaultApi.CreateAccount(ctx).CreateAccountRequest(createAccountRequest).Execute()
 		if err != nil {
-			log.Println(err.Error())
-			time.Sleep(60 * time.Second)
-			return resource.RetryableError(fmt.Errorf("error message: %s, http response: %s", err, body))
+			return resource.RetryableError(fmt.Errorf("error message: %w", err))
 		}
 
 		return nil
 	})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating account",
-			"Could not create account: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error creating account", "Could not create account", err)
 		return
 	}
 
 	var account map[string]interface{}
 	accName := "acc_" + plan.Name.Value
 	// Verify account created successfully
-	err = resource.RetryContext(ctx, timeout, func() *resource.RetryError {
+	err = poll(ctx, 15*time.Second, time.Minute, func() *resource.RetryError {
 		account, _, err = r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error getting account",
-				"Could not read account "+accName+": "+err.Error(),
-			)
+			return resource.NonRetryableError(err)
 		}
-		if account["status"].(string) == "" {
-			time.Sleep(60 * time.Second)
-			return resource.RetryableError(fmt.Errorf("waiting for account %s to finish creation", account["name"].(string)))
+		if status, _ := account["status"].(string); status == "" {
+			return resource.RetryableError(fmt.Errorf("waiting for account %s to finish creation", accName))
 		}
 
 		return nil
 	})
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting account", "Could not read account "+accName, err)
+		return
+	}
 
 	// Map response body to resource schema attribute
 	var result Account
@@ -279,6 +276,7 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		Id:          types.String{Value: account["id"].(string)},
 		Timeout:     plan.Timeout,
 		Concurrency: plan.Concurrency,
+		Timeouts:    plan.Timeouts,
 	}
 
 	// Generate resource state struct
@@ -291,6 +289,7 @@ func (r resourceAccount) Create(ctx context.Context, req tfsdk.CreateResourceReq
 
 // Read resource information
 func (r resourceAccount) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_account")
 	// Get current state
 	var state Account
 	diags := req.State.Get(ctx, &state)
@@ -298,20 +297,20 @@ func (r resourceAccount) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get account from API and then update what is in state from what the API returns
 	accName := "acc_" + state.Name.Value
 
 	// Get account current value
-	account, _, err := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting account",
-			"Could not read account "+accName+": "+err.Error(),
-		)
+	account, httpResponse, err := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error getting account", "Could not read account "+accName, err)
 		return
 	}
 	if account == nil {
+		logDebug(ctx, "Account not found, removing from state")
 		resp.State.RemoveResource(ctx)
 		return
 	}
@@ -331,10 +330,7 @@ func (r resourceAccount) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 
 	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing account details",
-			"Could not read account details"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing account details", "Could not read account details", err)
 		return
 	}
 	var roles []attr.Value
@@ -355,10 +351,7 @@ func (r resourceAccount) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 	}
 	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not read HA groups", err)
 		return
 	}
 	var hostGroupName = ""
@@ -390,6 +383,7 @@ func (r resourceAccount) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 		Id:          types.String{Value: account["id"].(string)},
 		Timeout:     state.Timeout,
 		Concurrency: state.Concurrency,
+		Timeouts:    state.Timeouts,
 	}
 
 	// Set state
@@ -402,6 +396,7 @@ func (r resourceAccount) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 
 // Update resource
 func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_account")
 	// Get plan values
 	var plan Account
 	diags := req.Plan.Get(ctx, &plan)
@@ -409,6 +404,8 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 10*time.Minute)
+	defer cancel()
 
 	// Get current state
 	var state Account
@@ -504,10 +501,7 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		if updateRolesAndPropagationLabels {
 			_, _, err = r.p.client.DefaultApi.UpdateAccount(ctx, plan.Name.Value).UpdateRolesAndPropagationLabelsRequest(updateRolesAndPropagationLabelsRequest).Execute()
 			if err != nil {
-				resp.Diagnostics.AddError(
-					"Error update account",
-					"Could not update account "+plan.Name.Value+": "+err.Error(),
-				)
+				addAPIError(&resp.Diagnostics, "Error update account", "Could not update account "+plan.Name.Value, err)
 				return
 			}
 		}
@@ -518,10 +512,7 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 	if plan.HostGroupName.Value != state.HostGroupName.Value {
 		haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error listing HA groups",
-				"Could not read HA groups"+err.Error(),
-			)
+			addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not read HA groups", err)
 			return
 		}
 		var targetHostGroupId = ""
@@ -533,10 +524,7 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		}
 		_, _, err = r.p.client.DefaultApi.UpdateAccountHost(ctx, "acc_"+plan.Name.Value, targetHostGroupId).Execute()
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error updating account host",
-				"Could not update account host for "+plan.Name.Value+": "+err.Error(),
-			)
+			addAPIAttributeError(&resp.Diagnostics, path.Root("host_group_name"), "Error updating account host", "Could not update account host for "+plan.Name.Value, err)
 			return
 		}
 	}
@@ -547,10 +535,7 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 	// Get account current value
 	account, _, err := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting account",
-			"Could not read account "+accName+": "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting account", "Could not read account "+accName, err)
 		return
 	}
 	if account == nil {
@@ -573,10 +558,7 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 
 	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing account details",
-			"Could not read account details"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing account details", "Could not read account details", err)
 		return
 	}
 	var roles []attr.Value
@@ -596,10 +578,7 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 	}
 	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not read HA groups", err)
 		return
 	}
 	var hostGroupName = ""
@@ -630,6 +609,7 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		Id:          types.String{Value: account["id"].(string)},
 		Timeout:     plan.Timeout,
 		Concurrency: plan.Concurrency,
+		Timeouts:    plan.Timeouts,
 	}
 
 	// Set state
@@ -642,6 +622,7 @@ func (r resourceAccount) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 
 // Delete resource
 func (r resourceAccount) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_account")
 	var state Account
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -650,44 +631,35 @@ func (r resourceAccount) Delete(ctx context.Context, req tfsdk.DeleteResourceReq
 	}
 
 	accName := "acc_" + state.Name.Value
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
 
-	err := resource.RetryContext(ctx, 300*time.Second, func() *resource.RetryError {
+	err := poll(ctx, 5*time.Second, 30*time.Second, func() *resource.RetryError {
 		// Get account current value
 		account, _, _ := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
 		if account != nil {
 			_, httpResponse, err := r.p.client.DefaultApi.DeleteAccount(ctx, accName).Execute()
-			if err != nil {
-				log.Println(err.Error())
-				if httpResponse != nil {
-					body, _ := io.ReadAll(httpResponse.Body)
-					payload, _ := io.ReadAll(httpResponse.Request.Body)
-					log.Printf("code: %d status: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body), string(payload))
-				}
-				return resource.RetryableError(fmt.Errorf("error deleting instance: %s", err))
+			if err != nil && !isNotFound(httpResponse, err) {
+				return resource.RetryableError(fmt.Errorf("error deleting instance: %w", err))
 			}
 		}
 		return nil
 	})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting account",
-			"Could not delete account: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error deleting account", "Could not delete account", err)
 		return
 	}
 	resp.State.RemoveResource(ctx)
 }
 
 func (r resourceAccount) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_account")
 	var diags diag.Diagnostics
 	accName := "acc_" + req.ID
 	// Get account current value
 	account, _, err := r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting account",
-			"Could not read account "+accName+": "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting account", "Could not read account "+accName, err)
 		return
 	}
 
@@ -706,10 +678,7 @@ func (r resourceAccount) ImportState(ctx context.Context, req tfsdk.ImportResour
 
 	details, _, err := r.p.client.DefaultApi.ListAccountsDetails(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing account details",
-			"Could not read account details"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing account details", "Could not read account details", err)
 		return
 	}
 	var roles []attr.Value
@@ -729,10 +698,7 @@ func (r resourceAccount) ImportState(ctx context.Context, req tfsdk.ImportResour
 	}
 	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not read HA groups", err)
 		return
 	}
 	var hostGroupName = ""
@@ -771,3 +737,12 @@ func (r resourceAccount) ImportState(ctx context.Context, req tfsdk.ImportResour
 		return
 	}
 }
+
+// ModifyPlan checks the provider is managing a multi-tenant main host
+func (r resourceAccount) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_account")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(r.p.validateMultiTenant("xsoar_account")...)
+}
@@ -3,16 +3,13 @@ package xsoar
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/badarsebard/xsoar-sdk-go/openapi"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
-	"log"
 	"net/http"
-	"strings"
+	"time"
 )
 
 type resourceClassifierType struct{}
@@ -54,9 +51,13 @@ func (r resourceClassifierType) GetSchema(_ context.Context) (tfsdk.Schema, diag
 			"account": {
 				Type:          types.StringType,
 				Optional:      true,
+				Computed:      true,
 				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -73,6 +74,7 @@ type resourceClassifier struct {
 
 // Create a new resource
 func (r resourceClassifier) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_classifier")
 	if !r.p.configured {
 		resp.Diagnostics.AddError(
 			"Provider not configured",
@@ -88,6 +90,8 @@ func (r resourceClassifier) Create(ctx context.Context, req tfsdk.CreateResource
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 5*time.Minute)
+	defer cancel()
 
 	// Create
 	classifierRequest := *openapi.NewCreateUpdateClassifierRequest()
@@ -127,23 +131,13 @@ func (r resourceClassifier) Create(ctx context.Context, req tfsdk.CreateResource
 		classifierRequest.SetPropagationLabels(props)
 	}
 	var classifier openapi.InstanceClassifier
-	var httpResponse *http.Response
-	if plan.Account.Null || len(plan.Account.Value) == 0 {
-		classifier, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(classifierRequest).Execute()
+	if accName, ok := r.p.accountName(plan.Account); !ok {
+		classifier, _, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(classifierRequest).Execute()
 	} else {
-		classifier, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(classifierRequest).Execute()
-	}
-	if httpResponse != nil {
-		getBody, _ := httpResponse.Request.GetBody()
-		b, _ := io.ReadAll(getBody)
-		log.Println(string(b))
+		classifier, _, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, accName).CreateUpdateClassifierAccountRequest(classifierRequest).Execute()
 	}
 	if err != nil {
-		log.Println(err.Error())
-		resp.Diagnostics.AddError(
-			"Error creating classifier",
-			"Could not create classifier: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error creating classifier", "Could not create classifier", err)
 		return
 	}
 
@@ -199,6 +193,8 @@ func (r resourceClassifier) Create(ctx context.Context, req tfsdk.CreateResource
 		result.Transformer = types.String{Value: v}
 	}
 
+	result.Timeouts = plan.Timeouts
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -209,6 +205,7 @@ func (r resourceClassifier) Create(ctx context.Context, req tfsdk.CreateResource
 
 // Read resource information
 func (r resourceClassifier) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_classifier")
 	// Get current state
 	var state Classifier
 	diags := req.State.Get(ctx, &state)
@@ -216,34 +213,26 @@ func (r resourceClassifier) Read(ctx context.Context, req tfsdk.ReadResourceRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get resource from API
 	var classifier openapi.InstanceClassifier
 	var httpResponse *http.Response
 	var err error
-	if state.Account.Null || len(state.Account.Value) == 0 {
+	if accName, ok := r.p.accountName(state.Account); !ok {
 		classifier, httpResponse, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(state.Name.Value).Execute()
 	} else {
-		classifier, httpResponse, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+state.Account.Value).SetIdentifier(state.Name.Value).Execute()
+		classifier, httpResponse, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, accName).SetIdentifier(state.Name.Value).Execute()
 	}
 	if err != nil {
-		// determine if the error is a not found error or not
-		if _, ok := err.(openapi.GenericOpenAPIError); ok {
-			log.Println("Classifier not found")
+		if isNotFound(httpResponse, err) {
+			logDebug(ctx, "Classifier not found, removing from state")
 			// Remove resource from state
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		log.Println(err.Error())
-		if httpResponse != nil {
-			getBody, _ := httpResponse.Request.GetBody()
-			b, _ := io.ReadAll(getBody)
-			log.Println(string(b))
-		}
-		resp.Diagnostics.AddError(
-			"Error getting classifier",
-			"Could not get classifier: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting classifier", "Could not get classifier", err)
 		return
 	}
 
@@ -299,6 +288,8 @@ func (r resourceClassifier) Read(ctx context.Context, req tfsdk.ReadResourceRequ
 		result.Transformer = types.String{Value: v}
 	}
 
+	result.Timeouts = state.Timeouts
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -309,6 +300,7 @@ func (r resourceClassifier) Read(ctx context.Context, req tfsdk.ReadResourceRequ
 
 // Update resource
 func (r resourceClassifier) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_classifier")
 	// Get plan values
 	var plan Classifier
 	diags := req.Plan.Get(ctx, &plan)
@@ -316,6 +308,8 @@ func (r resourceClassifier) Update(ctx context.Context, req tfsdk.UpdateResource
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 5*time.Minute)
+	defer cancel()
 
 	// Get current state
 	var state Classifier
@@ -364,23 +358,13 @@ func (r resourceClassifier) Update(ctx context.Context, req tfsdk.UpdateResource
 		classifierRequest.SetPropagationLabels(props)
 	}
 	var classifier openapi.InstanceClassifier
-	var httpResponse *http.Response
-	if plan.Account.Null || len(plan.Account.Value) == 0 {
-		classifier, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(classifierRequest).Execute()
+	if accName, ok := r.p.accountName(plan.Account); !ok {
+		classifier, _, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(classifierRequest).Execute()
 	} else {
-		classifier, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(classifierRequest).Execute()
+		classifier, _, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, accName).CreateUpdateClassifierAccountRequest(classifierRequest).Execute()
 	}
 	if err != nil {
-		log.Println(err.Error())
-		if httpResponse != nil {
-			getBody, _ := httpResponse.Request.GetBody()
-			b, _ := io.ReadAll(getBody)
-			log.Println(string(b))
-		}
-		resp.Diagnostics.AddError(
-			"Error updating classifier",
-			"Could not update classifier: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error updating classifier", "Could not update classifier", err)
 		return
 	}
 
@@ -436,6 +420,8 @@ func (r resourceClassifier) Update(ctx context.Context, req tfsdk.UpdateResource
 		result.Transformer = types.String{Value: v}
 	}
 
+	result.Timeouts = plan.Timeouts
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -446,6 +432,7 @@ func (r resourceClassifier) Update(ctx context.Context, req tfsdk.UpdateResource
 
 // Delete resource
 func (r resourceClassifier) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_classifier")
 	// Get state
 	var state Classifier
 	diags := req.State.Get(ctx, &state)
@@ -453,26 +440,19 @@ func (r resourceClassifier) Delete(ctx context.Context, req tfsdk.DeleteResource
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
 
 	// Delete
 	var httpResponse *http.Response
 	var err error
-	if state.Account.Null || len(state.Account.Value) == 0 {
+	if accName, ok := r.p.accountName(state.Account); !ok {
 		httpResponse, err = r.p.client.DefaultApi.DeleteClassifier(ctx, state.Id.Value).Execute()
 	} else {
-		httpResponse, err = r.p.client.DefaultApi.DeleteClassifierAccount(ctx, state.Id.Value, "acc_"+state.Account.Value).Execute()
+		httpResponse, err = r.p.client.DefaultApi.DeleteClassifierAccount(ctx, state.Id.Value, accName).Execute()
 	}
-	if err != nil {
-		log.Println(err.Error())
-		if httpResponse != nil {
-			getBody := httpResponse.Body
-			b, _ := io.ReadAll(getBody)
-			fmt.Println(string(b))
-		}
-		resp.Diagnostics.AddError(
-			"Error deleting mapper",
-			"Could not delete mapper: "+err.Error(),
-		)
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting mapper", "Could not delete mapper", err)
 		return
 	}
 
@@ -481,8 +461,9 @@ func (r resourceClassifier) Delete(ctx context.Context, req tfsdk.DeleteResource
 }
 
 func (r resourceClassifier) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_classifier")
 	var diags diag.Diagnostics
-	accname := strings.Split(req.ID, ".")
+	accname := r.p.splitImportId(req.ID)
 	var acc, name string
 	var classifier openapi.InstanceClassifier
 	var err error
@@ -494,10 +475,7 @@ func (r resourceClassifier) ImportState(ctx context.Context, req tfsdk.ImportRes
 		classifier, _, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+acc).SetIdentifier(name).Execute()
 	}
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error importing classifier",
-			"Could not import classifier: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error importing classifier", "Could not import classifier", err)
 		return
 	}
 	var propLabels []attr.Value
@@ -563,3 +541,13 @@ func (r resourceClassifier) ImportState(ctx context.Context, req tfsdk.ImportRes
 		return
 	}
 }
+
+// ModifyPlan plans the account, checking it is only given when the provider is managing a multi-tenant main host and
+// falling back to the provider's default account
+func (r resourceClassifier) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_classifier")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	r.p.planAccount(ctx, req, resp)
+}
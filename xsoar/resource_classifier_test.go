@@ -3,7 +3,7 @@ package xsoar
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -18,7 +18,7 @@ func TestAccClassifier_basic(t *testing.T) {
 		PreCheck: func() { testAccClassifierResourcePreCheck(t) },
 		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
 			"xsoar": func() (tfprotov6.ProviderServer, error) {
-				return tfsdk.NewProtocol6Server(New()), nil
+				return providerserver.NewProtocol6WithError(New()())()
 			},
 		},
 		CheckDestroy: testAccCheckClassifierResourceDestroy(rName),
@@ -33,10 +33,29 @@ func TestAccClassifier_basic(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			{
+				// A classifier deleted outside of Terraform is recreated
+				PreConfig: testAccDeleteClassifier(t, rName),
+				Config:    testAccClassifierResourceBasic(rName),
+				Check:     testAccCheckClassifierResourceExists(rName),
+			},
 		},
 	})
 }
 
+func testAccDeleteClassifier(t *testing.T, r string) func() {
+	return func() {
+		classifier, _, err := openapiClient.DefaultApi.GetClassifier(context.Background()).SetIdentifier(r).Execute()
+		if err != nil {
+			t.Fatalf("Error getting Classifier: %s", err)
+		}
+		_, err = openapiClient.DefaultApi.DeleteClassifier(context.Background(), classifier.GetId()).Execute()
+		if err != nil {
+			t.Fatalf("Error deleting Classifier: %s", err)
+		}
+	}
+}
+
 func testAccClassifierResourcePreCheck(t *testing.T) {}
 
 func testAccCheckClassifierResourceExists(r string) resource.TestCheckFunc {
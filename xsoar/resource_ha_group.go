@@ -7,8 +7,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
-	"log"
+	"time"
 )
 
 type resourceHAGroupType struct{}
@@ -46,6 +45,9 @@ func (r resourceHAGroupType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Di
 				Computed: true,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -62,6 +64,7 @@ type resourceHAGroup struct {
 
 // Create a new resource
 func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_ha_group")
 	if !r.p.configured {
 		resp.Diagnostics.AddError(
 			"Provider not configured",
@@ -69,6 +72,13 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		)
 		return
 	}
+	if !r.p.data.Server.supports(capabilityMultiTenantHosts) {
+		resp.Diagnostics.AddError(
+			"Unsupported server version",
+			"HA groups are only supported on multi-tenant XSOAR 6 main hosts, but the server is running XSOAR "+r.p.data.Server.Version+".",
+		)
+		return
+	}
 
 	// Retrieve values from plan
 	var plan HAGroup
@@ -77,6 +87,8 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 5*time.Minute)
+	defer cancel()
 
 	// Generate API request body from plan
 	createHAGroupRequest := *openapi.NewCreateHAGroupRequest()
@@ -87,39 +99,18 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 	// Create new HA group
 	haGroup, _, err := r.p.client.DefaultApi.CreateHAGroup(ctx).CreateHAGroupRequest(createHAGroupRequest).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating HA group",
-			"Could not create HA group "+plan.Name.Value+": "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error creating HA group", "Could not create HA group "+plan.Name.Value, err)
 		return
 	}
 
-	haGroup, httpResponse, err := r.p.client.DefaultApi.GetHAGroup(ctx, haGroup.GetId()).Execute()
-	if httpResponse != nil {
-		body, _ := io.ReadAll(httpResponse.Body)
-		payload, _ := io.ReadAll(httpResponse.Request.Body)
-		log.Printf("code: %d status: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body), string(payload))
-	}
+	haGroup, _, err = r.p.client.DefaultApi.GetHAGroup(ctx, haGroup.GetId()).Execute()
 	if err != nil {
-		log.Println(err.Error())
-		resp.Diagnostics.AddError(
-			"Error getting HA group",
-			"Could not get HA group: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting HA group", "Could not get HA group", err)
 		return
 	}
-	_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroup.GetId()).Execute()
-	if httpResponse != nil {
-		body, _ := io.ReadAll(httpResponse.Body)
-		payload, _ := io.ReadAll(httpResponse.Request.Body)
-		log.Printf("code: %d status: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body), string(payload))
-	}
+	_, _, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroup.GetId()).Execute()
 	if err != nil {
-		log.Println(err.Error())
-		resp.Diagnostics.AddError(
-			"Error creating HA installer",
-			"Could not create HA installer: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error creating HA installer", "Could not create HA installer", err)
 		return
 	}
 
@@ -160,6 +151,8 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 		result.HostIds.Elems = hostIds
 	}
 
+	result.Timeouts = plan.Timeouts
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -170,6 +163,7 @@ func (r resourceHAGroup) Create(ctx context.Context, req tfsdk.CreateResourceReq
 
 // Read resource information
 func (r resourceHAGroup) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_ha_group")
 	// Get current state
 	var state HAGroup
 	diags := req.State.Get(ctx, &state)
@@ -177,15 +171,18 @@ func (r resourceHAGroup) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get HA group from API and then update what is in state from what the API returns
-	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, state.Id.Value).Execute()
-	if err != nil {
+	haGroup, httpResponse, err := r.p.client.DefaultApi.GetHAGroup(ctx, state.Id.Value).Execute()
+	if isNotFound(httpResponse, err) {
+		logDebug(ctx, "HA group not found, removing from state")
 		resp.State.RemoveResource(ctx)
-		//resp.Diagnostics.AddError(
-		//	"Error getting HA group",
-		//	"Could not get HA group "+state.Name.Value+": "+err.Error(),
-		//)
+		return
+	}
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting HA group", "Could not get HA group "+state.Name.Value, err)
 		return
 	}
 
@@ -226,6 +223,8 @@ func (r resourceHAGroup) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 		result.HostIds.Elems = hostIds
 	}
 
+	result.Timeouts = state.Timeouts
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -236,6 +235,7 @@ func (r resourceHAGroup) Read(ctx context.Context, req tfsdk.ReadResourceRequest
 
 // Update resource
 func (r resourceHAGroup) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_ha_group")
 	// Get plan values
 	var plan HAGroup
 	diags := req.Plan.Get(ctx, &plan)
@@ -243,6 +243,8 @@ func (r resourceHAGroup) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 5*time.Minute)
+	defer cancel()
 
 	// Get current state
 	var state HAGroup
@@ -260,10 +262,7 @@ func (r resourceHAGroup) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 	updateHAGroupRequest.SetElasticIndexPrefix(plan.ElasticIndexPrefix.Value)
 	haGroup, _, err := r.p.client.DefaultApi.CreateHAGroup(ctx).CreateHAGroupRequest(updateHAGroupRequest).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error updating HA group",
-			"Could not update HA group "+plan.Name.Value+": "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error updating HA group", "Could not update HA group "+plan.Name.Value, err)
 		return
 	}
 
@@ -304,6 +303,8 @@ func (r resourceHAGroup) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 		result.HostIds.Elems = hostIds
 	}
 
+	result.Timeouts = plan.Timeouts
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -314,26 +315,20 @@ func (r resourceHAGroup) Update(ctx context.Context, req tfsdk.UpdateResourceReq
 
 // Delete resource
 func (r resourceHAGroup) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_ha_group")
 	var state HAGroup
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
 
-	// Verify existence
-	_, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, state.Id.Value).Execute()
-	if err != nil {
-		resp.State.RemoveResource(ctx)
-		return
-	}
 	// Delete HA group by calling API
-	_, _, err = r.p.client.DefaultApi.DeleteHAGroup(ctx, state.Id.Value).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting HA group",
-			"Could not delete HA group "+state.Name.Value+": "+err.Error(),
-		)
+	_, httpResponse, err := r.p.client.DefaultApi.DeleteHAGroup(ctx, state.Id.Value).Execute()
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting HA group", "Could not delete HA group "+state.Name.Value, err)
 		return
 	}
 
@@ -342,15 +337,13 @@ func (r resourceHAGroup) Delete(ctx context.Context, req tfsdk.DeleteResourceReq
 }
 
 func (r resourceHAGroup) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_ha_group")
 	var diags diag.Diagnostics
 	name := req.ID
 	// Get HA group current value
 	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing HA groups",
-			"Could not read HA groups"+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not read HA groups", err)
 		return
 	}
 	var id string
@@ -362,10 +355,7 @@ func (r resourceHAGroup) ImportState(ctx context.Context, req tfsdk.ImportResour
 	}
 	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, id).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting HA group",
-			"Could not read HA group "+name+": "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting HA group", "Could not read HA group "+name, err)
 		return
 	}
 
@@ -413,3 +403,12 @@ func (r resourceHAGroup) ImportState(ctx context.Context, req tfsdk.ImportResour
 		return
 	}
 }
+
+// ModifyPlan checks the provider is managing a multi-tenant main host
+func (r resourceHAGroup) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_ha_group")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(r.p.validateMultiTenant("xsoar_ha_group")...)
+}
@@ -3,7 +3,7 @@ package xsoar
 import (
 	"context"
 	"fmt"
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -18,7 +18,7 @@ func TestAccHAGroup_basic(t *testing.T) {
 		PreCheck: func() { testAccHAGroupResourcePreCheck(t) },
 		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
 			"xsoar": func() (tfprotov6.ProviderServer, error) {
-				return tfsdk.NewProtocol6Server(New()), nil
+				return providerserver.NewProtocol6WithError(New()())()
 			},
 		},
 		CheckDestroy: testAccCheckHAGroupResourceDestroy(rName),
@@ -33,6 +33,13 @@ func TestAccHAGroup_basic(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			{
+				Config: testAccHAGroupResourceTimeouts(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckHAGroupResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_ha_group."+rName, "timeouts.0.create", "10m"),
+				),
+			},
 		},
 	})
 }
@@ -90,3 +97,18 @@ resource "xsoar_ha_group" "{name}" {
 	c = strings.Replace(c, "{name}", name, -1)
 	return c
 }
+
+func testAccHAGroupResourceTimeouts(name string) string {
+	c := `
+resource "xsoar_ha_group" "{name}" {
+  name                 = "{name}"
+  elasticsearch_url    = "http://elastic.xsoar.local:9200"
+  elastic_index_prefix = "{name}_"
+  timeouts {
+    create = "10m"
+    delete = "10m"
+  }
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
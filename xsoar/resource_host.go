@@ -4,16 +4,17 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"golang.org/x/crypto/ssh"
 	"hash/crc64"
-	"io"
-	"log"
 	"math/rand"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 )
@@ -62,18 +63,138 @@ func (r resourceHostType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagn
 			},
 			"ssh_key": {
 				Type:      types.StringType,
-				Required:  true,
+				Optional:  true,
 				Sensitive: true,
 			},
+			"ssh_key_passphrase": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_certificate": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"ssh_password": {
+				Type:      types.StringType,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"ssh_agent": {
+				Type:     types.BoolType,
+				Optional: true,
+			},
+			"host_key": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"known_hosts": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"known_hosts_file": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"force_destroy": {
+				Type:     types.BoolType,
+				Optional: true,
+			},
+			"drain_to_host_group": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"installed_version": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"server_version": {
+				Type:     types.StringType,
+				Computed: true,
+			},
 			"installation_timeout": {
 				Type:     types.Int64Type,
 				Optional: true,
 			},
 			"extra_flags": {
-				Type:     types.ListType{ElemType: types.StringType},
-				Optional: true,
+				Type:       types.ListType{ElemType: types.StringType},
+				Optional:   true,
+				Validators: []tfsdk.AttributeValidator{isValidInstallerFlags{}},
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"installer_options": {
+				NestingMode: tfsdk.BlockNestingModeList,
+				MaxItems:    1,
+				Attributes: map[string]tfsdk.Attribute{
+					"external_address": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"temp_folder": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"http_proxy": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"https_proxy": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"docker_network": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"dockerd_options": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"install_tools": {
+						Type:     types.BoolType,
+						Optional: true,
+					},
+					"multi_tenant": {
+						Type:     types.BoolType,
+						Optional: true,
+					},
+					"do_not_start_server": {
+						Type:     types.BoolType,
+						Optional: true,
+					},
+				},
+			},
+			"bastion": {
+				NestingMode: tfsdk.BlockNestingModeList,
+				MaxItems:    1,
+				Attributes: map[string]tfsdk.Attribute{
+					"address": {
+						Type:     types.StringType,
+						Required: true,
+					},
+					"ssh_user": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+					"ssh_key": {
+						Type:      types.StringType,
+						Optional:  true,
+						Sensitive: true,
+					},
+					"ssh_key_passphrase": {
+						Type:      types.StringType,
+						Optional:  true,
+						Sensitive: true,
+					},
+					"host_key": {
+						Type:     types.StringType,
+						Optional: true,
+					},
+				},
+			},
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -90,7 +211,7 @@ type resourceHost struct {
 
 // Create a new resource
 func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
-	log.Println("Starting create")
+	ctx = newLogContext(ctx, "xsoar_host")
 	if !r.p.configured {
 		resp.Diagnostics.AddError(
 			"Provider not configured",
@@ -98,6 +219,13 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 		)
 		return
 	}
+	if !r.p.data.Server.supports(capabilityMultiTenantHosts) {
+		resp.Diagnostics.AddError(
+			"Unsupported server version",
+			"Hosts are only supported on multi-tenant XSOAR 6 main hosts, but the server is running XSOAR "+r.p.data.Server.Version+".",
+		)
+		return
+	}
 
 	// Retrieve values from plan
 	var plan Host
@@ -106,7 +234,8 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	log.Printf("%+v\n", plan)
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 30*time.Minute)
+	defer cancel()
 
 	var isHA bool
 	if !plan.HAGroupName.Null && len(plan.HAGroupName.Value) > 0 {
@@ -123,145 +252,71 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 	}
 
 	// 1) connect to host server over ssh
-	apikey := r.p.data.Apikey
-	mainhost := r.p.data.MainHost
-	signer, _ := ssh.ParsePrivateKey([]byte(plan.SSHKey.Value))
-	clientConfig := ssh.ClientConfig{
-		User: plan.SSHUser.Value,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-	var conn *ssh.Client
-	var err error
-	err = resource.RetryContext(ctx, 300*time.Second, func() *resource.RetryError {
-		var conErr error
-		conn, conErr = ssh.Dial("tcp", plan.ServerUrl.Value, &clientConfig)
-		if conErr != nil {
-			return resource.RetryableError(fmt.Errorf("error connecting to host over ssh: " + conErr.Error()))
-		}
-		return nil
-	})
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating host",
-			fmt.Sprintf("Error creating host: %s", err.Error()),
-		)
+	conn, diags := dialHost(ctx, plan, 300*time.Second)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 	defer conn.Close()
+	var err error
 
 	// 2) query main server with /host/build
-	var haGroup string
-	var httpResponse *http.Response
+	var haGroupId string
 	if isHA {
 		var haGroups []map[string]interface{}
-		var haGroupId string
-		log.Println("List ha groups")
+		logDebug(ctx, "Listing HA groups")
 		haGroups, _, err = r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error listing HA groups",
-				"Could not list HA groups: "+err.Error(),
-			)
+			addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not list HA groups", err)
 			return
 		}
 		for _, group := range haGroups {
 			if group["name"].(string) == plan.HAGroupName.Value {
 				haGroupId = group["id"].(string)
-				haGroup = "/" + haGroupId
-			}
-		}
-		_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
-		if err != nil {
-			log.Println(err.Error())
-			body, _ := io.ReadAll(httpResponse.Body)
-			log.Printf("code: %d status: %s body: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body))
-			i := bytes.Index(body, []byte("Already building host for ha group"))
-			if i > -1 {
-				for true {
-					_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
-					if err == nil {
-						break
-					}
-				}
-			} else {
-				resp.Diagnostics.AddError(
-					"Error creating HA installer",
-					"Could not create HA installer: "+err.Error(),
-				)
-				return
-			}
-		}
-	} else {
-		_, httpResponse, err = r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
-		if err != nil {
-			log.Println(err.Error())
-			body, bodyErr := io.ReadAll(httpResponse.Body)
-			if bodyErr != nil {
-				log.Println("error reading body: " + bodyErr.Error())
-				return
-			}
-			log.Printf("code: %d status: %s body: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body))
-			i := bytes.Index(body, []byte("Already building host installer"))
-			if i > -1 {
-				for true {
-					_, httpResponse, err = r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
-					if err == nil {
-						break
-					}
-				}
-			} else {
-				resp.Diagnostics.AddError(
-					"Error creating host installer",
-					"Could not create host installer: "+err.Error(),
-				)
-				return
 			}
 		}
 	}
-
-	// 3) download installer
-	session, err := conn.NewSession()
+	err = r.buildInstaller(ctx, haGroupId)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating ssh session",
-			"Could not create ssh session: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error creating host installer", "Could not create host installer", err)
 		return
 	}
-	defer session.Close()
 
-	insecure := ""
-	if r.p.data.Insecure.Value {
-		insecure = "-k"
+	// 3) download installer and copy it to the host
+	installer, err := r.downloadInstaller(ctx, haGroupId)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error downloading installer", "Could not download installer", err)
+		return
 	}
-	cmd := fmt.Sprintf(
-		"sudo curl -s -o '/tmp/installer.sh' -H 'Authorization: %s' %s %s/host/download%s && "+
-			"sudo chmod +x /tmp/installer.sh",
-		apikey.Value, insecure, mainhost.Value, haGroup)
-	err = session.Run(cmd)
+	defer os.Remove(installer.Name())
+	defer installer.Close()
+	err = uploadFile(conn, installer, "/tmp/installer.sh", 0700)
 	if err != nil {
-
 		resp.Diagnostics.AddError(
-			"Error downloading installer",
-			"Could not download installer: "+err.Error(),
+			"Error copying installer",
+			"Could not copy installer to host: "+err.Error(),
 		)
 		return
 	}
+	var session *ssh.Session
 
 	// 4) Check for lock
 	if !plan.NFSMount.Null {
 		// wait a random amount of time
 		crcTable := crc64.MakeTable(crc64.ISO)
 		seedInt := int64(crc64.Checksum([]byte(plan.Name.Value), crcTable))
-		log.Printf("generated seed: %d\n", seedInt)
 		randSource := rand.NewSource(seedInt)
 		nrand := rand.New(randSource)
 		randomTimeToWait := nrand.Intn(30) + 1
-		log.Printf("sleeping for %d seconds\n", randomTimeToWait)
-		time.Sleep(time.Duration(randomTimeToWait) * time.Second)
+		logDebug(ctx, "Waiting for installation lock", map[string]interface{}{"seconds": randomTimeToWait})
+		err = sleep(ctx, time.Duration(randomTimeToWait)*time.Second)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error waiting for lock file",
+				"Lock file error: "+err.Error(),
+			)
+			return
+		}
 		// attempt to place lock
 		session, err = conn.NewSession()
 		if err != nil {
@@ -272,7 +327,7 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 			return
 		}
 		defer session.Close()
-		err = session.Run(fmt.Sprintf(
+		err = runCommand(ctx, session, fmt.Sprintf(
 			`while [[ -f "%s/xsoar_host_install.lock" ]]; do sleep %d; done; sudo touch %s/xsoar_host_install.lock`,
 			plan.NFSMount.Value, randomTimeToWait, plan.NFSMount.Value,
 		))
@@ -286,7 +341,7 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 	}
 
 	// 5) Execute installer
-	log.Println("Executing install")
+	logDebug(ctx, "Running installer")
 	session, err = conn.NewSession()
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -297,45 +352,25 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 	}
 	defer session.Close()
 
-	var args = []string{
-		"-y",
-		"-external-address='" + plan.Name.Value + "'",
-	}
-	if isElastic && !isHA {
-		args = append(args, "-elasticsearch-url='"+plan.ElasticsearchUrl.Value+"'")
-	}
-	if isHA {
-		args = append(args, "-temp-folder='/tmp/demisto'", "-ha")
-	}
-	if !plan.ExtraFlags.Null {
-		var extraArgs []string
-		flagErr := plan.ExtraFlags.ElementsAs(ctx, &extraArgs, false)
-		if flagErr != nil {
-			resp.Diagnostics.AddError(
-				"Error extracting extra arguments",
-				fmt.Sprintf("Could not extract %s into extraArgs with error: %s", plan.ExtraFlags.Elems, flagErr),
-			)
-			return
-		}
-		log.Printf("extra args: %s\n", extraArgs)
-		// todo: there's a security flaw here where a user can inject arbitrary commands into the installer
-		args = append(args, extraArgs...)
+	args, diags := installerArgs(ctx, plan, isHA, isElastic)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 	argsString := strings.Join(args, " ")
 
-	err = session.Run("sudo /tmp/installer.sh -- " + argsString)
-	log.Printf("args: %s", argsString)
+	logDebug(ctx, "Installer arguments", map[string]interface{}{"flags": strings.Join(installerArgNames(args), " ")})
+	err = runCommand(ctx, session, "sudo /tmp/installer.sh -- "+argsString)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error running installer",
 			"Could not run installer: "+err.Error(),
 		)
-		log.Println("remove lock file")
+		logDebug(ctx, "Removing installation lock")
 		session, err = conn.NewSession()
 		defer session.Close()
 		err = session.Run(fmt.Sprintf("sudo rm -f %s/xsoar_host_install.lock", plan.NFSMount.Value))
 		if err != nil {
-			log.Println("could not remove lock file")
 			resp.Diagnostics.AddError(
 				"Error removing lock file",
 				"Could not remove lock file: "+err.Error(),
@@ -345,31 +380,15 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 	}
 
 	// Verify host details
-	log.Println("Verifying host details")
-	var host = make(map[string]interface{})
-	c1 := make(chan map[string]interface{}, 1)
-	go func() {
-		for len(host) == 0 || host["hostGroupId"] == "" {
-			host, _, _ = r.p.client.DefaultApi.GetHost(ctx, plan.Name.Value).Execute()
-			time.Sleep(time.Second)
-		}
-		c1 <- host
-	}()
-	timeout := time.Duration(300)
-	if !plan.InstallationTimeout.Null {
-		timeout = time.Duration(plan.InstallationTimeout.Value)
-	}
-	select {
-	case _ = <-c1:
-		log.Println(host)
-		break
-	case <-time.After(timeout * time.Second):
-		resp.Diagnostics.AddError(
-			"Error getting host",
-			"Could not get host before timeout",
-		)
+	logDebug(ctx, "Waiting for host to register")
+	installCtx, installCancel := withInstallationTimeout(ctx, plan)
+	defer installCancel()
+	host, err := waitForHost(installCtx, r.p.client, plan.Name.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting host", "Could not get host before timeout", err)
 		return
 	}
+	logDebug(ctx, "Host registered", map[string]interface{}{"host_group_id": host["hostGroupId"]})
 	// delete lock file
 	if !plan.NFSMount.Null {
 		session, err = conn.NewSession()
@@ -396,18 +415,9 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 	var hostId = host["id"].(string)
 	var hostGroupId = host["hostGroupId"].(string)
 
-	haGroupName, httpResponse, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
+	haGroupName, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
 	if err != nil {
-		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
-		resp.Diagnostics.AddError(
-			"Error getting HA group",
-			"Could not get HA group: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting HA group", "Could not get HA group", err)
 		return
 	}
 
@@ -416,11 +426,22 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 		Name:                types.String{Value: hostName},
 		Id:                  types.String{Value: hostId},
 		InstallationTimeout: plan.InstallationTimeout,
+		ForceDestroy:        plan.ForceDestroy,
+		DrainToHostGroup:    plan.DrainToHostGroup,
 		ExtraFlags:          plan.ExtraFlags,
+		InstallerOptions:    plan.InstallerOptions,
 		NFSMount:            plan.NFSMount,
 		ServerUrl:           plan.ServerUrl,
 		SSHUser:             plan.SSHUser,
 		SSHKey:              plan.SSHKey,
+		SSHKeyPassphrase:    plan.SSHKeyPassphrase,
+		SSHCertificate:      plan.SSHCertificate,
+		SSHPassword:         plan.SSHPassword,
+		SSHAgent:            plan.SSHAgent,
+		HostKey:             plan.HostKey,
+		KnownHosts:          plan.KnownHosts,
+		KnownHostsFile:      plan.KnownHostsFile,
+		Bastion:             plan.Bastion,
 	}
 
 	if host["host"].(string) != haGroupName.GetName() {
@@ -434,6 +455,10 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 	} else {
 		result.ElasticsearchUrl.Null = true
 	}
+	result.InstalledVersion = hostVersion(host)
+	result.ServerVersion = r.mainVersion()
+
+	result.Timeouts = plan.Timeouts
 
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
@@ -445,6 +470,7 @@ func (r resourceHost) Create(ctx context.Context, req tfsdk.CreateResourceReques
 
 // Read resource information
 func (r resourceHost) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_host")
 	// Get current state
 	var state Host
 	diags := req.State.Get(ctx, &state)
@@ -452,32 +478,17 @@ func (r resourceHost) Read(ctx context.Context, req tfsdk.ReadResourceRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
-	var host map[string]interface{}
-	var err error
-	c1 := make(chan map[string]interface{}, 1)
-	go func() {
-		for host == nil {
-			host, _, err = r.p.client.DefaultApi.GetHost(ctx, state.Name.Value).Execute()
-			if err != nil {
-				resp.Diagnostics.AddError(
-					"Error listing HA groups",
-					"Could not list HA groups: "+err.Error(),
-				)
-				return
-			}
-			time.Sleep(time.Second)
-		}
-		c1 <- host
-	}()
-	select {
-	case _ = <-c1:
-		break
-	case <-time.After(60 * time.Second):
-		resp.Diagnostics.AddError(
-			"Error getting host",
-			"Could not get host before timeout",
-		)
+	host, httpResponse, err := r.p.client.DefaultApi.GetHost(ctx, state.Name.Value).Execute()
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error getting host", "Could not get host", err)
+		return
+	}
+	if host == nil {
+		logDebug(ctx, "Host not found, removing from state")
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
@@ -486,18 +497,9 @@ func (r resourceHost) Read(ctx context.Context, req tfsdk.ReadResourceRequest, r
 	var hostId = host["id"].(string)
 	var hostGroupId = host["hostGroupId"].(string)
 
-	haGroupName, httpResponse, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
+	haGroupName, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
 	if err != nil {
-		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
-		resp.Diagnostics.AddError(
-			"Error getting HA group",
-			"Could not get HA group: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting HA group", "Could not get HA group", err)
 		return
 	}
 
@@ -506,11 +508,22 @@ func (r resourceHost) Read(ctx context.Context, req tfsdk.ReadResourceRequest, r
 		Name:                types.String{Value: hostName},
 		Id:                  types.String{Value: hostId},
 		InstallationTimeout: state.InstallationTimeout,
+		ForceDestroy:        state.ForceDestroy,
+		DrainToHostGroup:    state.DrainToHostGroup,
 		ExtraFlags:          state.ExtraFlags,
+		InstallerOptions:    state.InstallerOptions,
 		NFSMount:            state.NFSMount,
 		ServerUrl:           state.ServerUrl,
 		SSHUser:             state.SSHUser,
 		SSHKey:              state.SSHKey,
+		SSHKeyPassphrase:    state.SSHKeyPassphrase,
+		SSHCertificate:      state.SSHCertificate,
+		SSHPassword:         state.SSHPassword,
+		SSHAgent:            state.SSHAgent,
+		HostKey:             state.HostKey,
+		KnownHosts:          state.KnownHosts,
+		KnownHostsFile:      state.KnownHostsFile,
+		Bastion:             state.Bastion,
 	}
 
 	if host["host"].(string) != haGroupName.GetName() {
@@ -524,6 +537,10 @@ func (r resourceHost) Read(ctx context.Context, req tfsdk.ReadResourceRequest, r
 	} else {
 		result.ElasticsearchUrl.Null = true
 	}
+	result.InstalledVersion = hostVersion(host)
+	result.ServerVersion = r.mainVersion()
+
+	result.Timeouts = state.Timeouts
 
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
@@ -535,6 +552,7 @@ func (r resourceHost) Read(ctx context.Context, req tfsdk.ReadResourceRequest, r
 
 // Update resource
 func (r resourceHost) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_host")
 	// Get plan values
 	var plan Host
 	diags := req.Plan.Get(ctx, &plan)
@@ -542,6 +560,8 @@ func (r resourceHost) Update(ctx context.Context, req tfsdk.UpdateResourceReques
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 30*time.Minute)
+	defer cancel()
 
 	// Get current state
 	var state Host
@@ -555,6 +575,23 @@ func (r resourceHost) Update(ctx context.Context, req tfsdk.UpdateResourceReques
 	// the only attributes which are changeable are ones not available through the API about the host itself
 	result := plan
 	result.Id = state.Id
+	result.InstalledVersion = state.InstalledVersion
+	if plan.ServerVersion.Unknown {
+		result.ServerVersion = r.mainVersion()
+	}
+
+	// Upgrade the host in place when ModifyPlan has found it behind the main server
+	if !plan.InstalledVersion.Unknown && !plan.InstalledVersion.Null && !sameVersion(plan.InstalledVersion.Value, state.InstalledVersion.Value) {
+		logDebug(ctx, "Upgrading host", map[string]interface{}{"from": state.InstalledVersion.Value, "to": plan.InstalledVersion.Value})
+		version, diags := r.upgrade(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// Keep the planned version, which the host's may only differ from in format
+		logDebug(ctx, "Upgraded host", map[string]interface{}{"version": version})
+		result.InstalledVersion = plan.InstalledVersion
+	}
 
 	// Set state
 	diags = resp.State.Set(ctx, result)
@@ -566,12 +603,15 @@ func (r resourceHost) Update(ctx context.Context, req tfsdk.UpdateResourceReques
 
 // Delete resource
 func (r resourceHost) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_host")
 	var state Host
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 30*time.Minute)
+	defer cancel()
 
 	var isHA bool
 	if !state.HAGroupName.Null && len(state.HAGroupName.Value) > 0 {
@@ -580,140 +620,68 @@ func (r resourceHost) Delete(ctx context.Context, req tfsdk.DeleteResourceReques
 		isHA = false
 	}
 
+	// Make sure destroying the host doesn't leave accounts without one
+	gone, diags := r.drain(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if gone {
+		logDebug(ctx, "Host not found, removing from state")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
 	// Delete Host
 	// 1) connect to host server over ssh
-	apikey := r.p.data.Apikey
-	mainhost := r.p.data.MainHost
-	signer, _ := ssh.ParsePrivateKey([]byte(state.SSHKey.Value))
-	clientConfig := ssh.ClientConfig{
-		User: state.SSHUser.Value,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-	var conn *ssh.Client
-	var err error
-	err = resource.RetryContext(ctx, 300*time.Second, func() *resource.RetryError {
-		var conErr error
-		conn, conErr = ssh.Dial("tcp", state.ServerUrl.Value, &clientConfig)
-		if conErr != nil {
-			return resource.RetryableError(fmt.Errorf("error connecting to host over ssh: " + conErr.Error()))
-		}
-		return nil
-	})
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting host",
-			"Could not delete host: "+err.Error(),
-		)
+	conn, diags := dialHost(ctx, state, 300*time.Second)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 	defer conn.Close()
+	var err error
 
 	// 2) query main server with /host/build
-	var haGroup string
-	var httpResponse *http.Response
+	var haGroupId string
 	if isHA {
 		var haGroups []map[string]interface{}
-		var haGroupId string
-		log.Println("List ha groups")
+		logDebug(ctx, "Listing HA groups")
 		haGroups, _, err = r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error listing HA groups",
-				"Could not list HA groups: "+err.Error(),
-			)
+			addAPIError(&resp.Diagnostics, "Error listing HA groups", "Could not list HA groups", err)
 			return
 		}
 		for _, group := range haGroups {
 			if group["name"].(string) == state.HAGroupName.Value {
 				haGroupId = group["id"].(string)
-				haGroup = "/" + haGroupId
-			}
-		}
-		_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
-		if err != nil {
-			body, bodyErr := io.ReadAll(httpResponse.Body)
-			if bodyErr != nil {
-				log.Println("error reading body: " + bodyErr.Error())
-				return
-			}
-			log.Printf("code: %d status: %s body: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body))
-			i := bytes.Index(body, []byte("Already building host for ha group"))
-			if i > -1 {
-				for true {
-					_, httpResponse, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
-					if err == nil {
-						break
-					}
-				}
-			} else {
-				resp.Diagnostics.AddError(
-					"Error creating HA installer",
-					"Could not create HA installer: "+err.Error(),
-				)
-				return
-			}
-		}
-	} else {
-		_, _, err = r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
-		if err != nil {
-			body, bodyErr := io.ReadAll(httpResponse.Body)
-			if bodyErr != nil {
-				log.Println("error reading body: " + bodyErr.Error())
-				return
-			}
-			log.Printf("code: %d status: %s body: %s\n", httpResponse.StatusCode, httpResponse.Status, string(body))
-			i := bytes.Index(body, []byte("Already building host for ha group"))
-			if i > -1 {
-				for true {
-					_, httpResponse, err = r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
-					if err == nil {
-						break
-					}
-				}
-			} else {
-				resp.Diagnostics.AddError(
-					"Error creating host installer",
-					"Could not create host installer: "+err.Error(),
-				)
-				return
 			}
 		}
 	}
-
-	// 3) download installer
-	session, err := conn.NewSession()
+	err = r.buildInstaller(ctx, haGroupId)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating ssh session",
-			"Could not create ssh session: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error creating host installer", "Could not create host installer", err)
 		return
 	}
-	defer session.Close()
 
-	insecure := ""
-	if r.p.data.Insecure.Value {
-		insecure = "-k"
+	// 3) download installer and copy it to the host
+	installer, err := r.downloadInstaller(ctx, haGroupId)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error downloading installer", "Could not download installer", err)
+		return
 	}
-	cmd := fmt.Sprintf(
-		"sudo curl -s -o '/tmp/installer.sh' -H 'Authorization: %s' %s %s/host/download%s && "+
-			"sudo chmod +x /tmp/installer.sh",
-		apikey.Value, insecure, mainhost.Value, haGroup)
-	err = session.Run(cmd)
+	defer os.Remove(installer.Name())
+	defer installer.Close()
+	err = uploadFile(conn, installer, "/tmp/installer.sh", 0700)
 	if err != nil {
-		fmt.Println(cmd)
 		resp.Diagnostics.AddError(
-			"Error downloading installer",
-			"Could not download installer: "+err.Error(),
+			"Error copying installer",
+			"Could not copy installer to host: "+err.Error(),
 		)
 		return
 	}
-
 	// 4) Execute installer
-	session, err = conn.NewSession()
+	session, err := conn.NewSession()
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error creating ssh session",
@@ -723,7 +691,7 @@ func (r resourceHost) Delete(ctx context.Context, req tfsdk.DeleteResourceReques
 	}
 	defer session.Close()
 
-	err = session.Run("sudo /tmp/installer.sh -- -purge -y")
+	err = runCommand(ctx, session, "sudo /tmp/installer.sh -- -purge -y")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error running installer",
@@ -733,12 +701,9 @@ func (r resourceHost) Delete(ctx context.Context, req tfsdk.DeleteResourceReques
 	}
 
 	// Delete host from main
-	_, _, err = r.p.client.DefaultApi.DeleteHost(ctx, state.Id.Value).Execute()
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting host",
-			"Could not delete host: "+err.Error(),
-		)
+	_, httpResponse, err := r.p.client.DefaultApi.DeleteHost(ctx, state.Id.Value).Execute()
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting host", "Could not delete host", err)
 		return
 	}
 
@@ -747,34 +712,16 @@ func (r resourceHost) Delete(ctx context.Context, req tfsdk.DeleteResourceReques
 }
 
 func (r resourceHost) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_host")
 	var diags diag.Diagnostics
 	name := req.ID
 
-	var host map[string]interface{}
-	var err error
-	c1 := make(chan map[string]interface{}, 1)
-	go func() {
-		for host == nil {
-			host, _, err = r.p.client.DefaultApi.GetHost(ctx, name).Execute()
-			if err != nil {
-				resp.Diagnostics.AddError(
-					"Error getting host",
-					"Could not get host: "+err.Error(),
-				)
-				return
-			}
-			time.Sleep(time.Second)
-		}
-		c1 <- host
-	}()
-	select {
-	case _ = <-c1:
-		break
-	case <-time.After(60 * time.Second):
-		resp.Diagnostics.AddError(
-			"Error getting host",
-			"Could not get host before timeout",
-		)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	host, err := waitForHost(ctx, r.p.client, name)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting host", "Could not get host", err)
 		return
 	}
 
@@ -784,10 +731,7 @@ func (r resourceHost) ImportState(ctx context.Context, req tfsdk.ImportResourceS
 
 	haGroup, _, err := r.p.client.DefaultApi.GetHAGroup(ctx, hostGroupId).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting HA group",
-			"Could not get HA group: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting HA group", "Could not get HA group", err)
 		return
 	}
 
@@ -815,6 +759,14 @@ func (r resourceHost) ImportState(ctx context.Context, req tfsdk.ImportResourceS
 	} else {
 		result.ElasticsearchUrl.Null = true
 	}
+	result.InstalledVersion = hostVersion(host)
+	result.ServerVersion = r.mainVersion()
+
+	// The import ID only names the host, so there is no SSH address to check a host key against
+	resp.Diagnostics.AddWarning(
+		"Host key not verified on import",
+		"Importing "+hostName+" does not connect to it over SSH, so its host key is not checked. The key is first verified against the configured host_key, known_hosts or known_hosts_file the next time the provider connects to the host.",
+	)
 
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
@@ -823,3 +775,306 @@ func (r resourceHost) ImportState(ctx context.Context, req tfsdk.ImportResourceS
 		return
 	}
 }
+
+// buildInstaller asks the main server to build the host installer, or the installer for the HA group if haGroupId is
+// set, waiting while another build for it is still running.
+func (r resourceHost) buildInstaller(ctx context.Context, haGroupId string) error {
+	return poll(ctx, 5*time.Second, 30*time.Second, func() *resource.RetryError {
+		var err error
+		if len(haGroupId) > 0 {
+			_, _, err = r.p.client.DefaultApi.CreateHAInstaller(ctx, haGroupId).Execute()
+		} else {
+			_, _, err = r.p.client.DefaultApi.CreateHostInstaller(ctx).Execute()
+		}
+		if err != nil {
+			if apiErr, ok := err.(openapi.GenericOpenAPIError); ok && bytes.Contains(apiErr.Body(), []byte("Already building")) {
+				return resource.RetryableError(err)
+			}
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+}
+
+// downloadInstaller fetches the host installer, or the installer for the HA group if haGroupId is set, from the main
+// host through the API client so the API key never has to be passed to the host itself.
+func (r resourceHost) downloadInstaller(ctx context.Context, haGroupId string) (*os.File, error) {
+	var installer *os.File
+	var err error
+	if len(haGroupId) > 0 {
+		installer, _, err = r.p.client.DefaultApi.GetHAInstaller(ctx, haGroupId).Execute()
+	} else {
+		installer, _, err = r.p.client.DefaultApi.GetHostInstaller(ctx).Execute()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if installer == nil {
+		return nil, fmt.Errorf("empty response from main host")
+	}
+	return installer, nil
+}
+
+// ModifyPlan checks the provider is managing a multi-tenant main host, and plans an upgrade of the host when the
+// version it reports has drifted from that of the main server
+func (r resourceHost) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_host")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(r.p.validateMultiTenant("xsoar_host")...)
+	if resp.Diagnostics.HasError() || req.State.Raw.IsNull() || !r.p.configured {
+		return
+	}
+	var state Host
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Versions are compared normalized, as hosts and the main server don't format them the same way. The host is only
+	// planned to change to the main server's version when they are different releases.
+	serverVersion := r.mainVersion()
+	target := state.InstalledVersion
+	if serverVersion.Null {
+		logDebug(ctx, "Server version unknown, not checking the host for upgrades")
+		serverVersion = state.ServerVersion
+	} else if !state.InstalledVersion.Null && !state.InstalledVersion.Unknown && !sameVersion(serverVersion.Value, state.InstalledVersion.Value) {
+		target = serverVersion
+	}
+	diags = resp.Plan.SetAttribute(ctx, path.Root("installed_version"), target)
+	resp.Diagnostics.Append(diags...)
+	diags = resp.Plan.SetAttribute(ctx, path.Root("server_version"), serverVersion)
+	resp.Diagnostics.Append(diags...)
+}
+
+// mainVersion returns the version of the main server the provider connected to, or null if it could not be looked up
+func (r resourceHost) mainVersion() types.String {
+	if r.p.data.Server == nil {
+		return types.String{Null: true}
+	}
+	return types.String{Value: r.p.data.Server.Version}
+}
+
+// upgrade runs the installer for the main server's current version over an existing installation and waits for the
+// host to report the new version, which is returned. The installer has no separate upgrade flag: it detects the
+// existing installation and upgrades it in place, keeping its data and configuration, and -y accepts the upgrade.
+func (r resourceHost) upgrade(ctx context.Context, plan Host) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	isHA := !plan.HAGroupName.Null && len(plan.HAGroupName.Value) > 0
+	isElastic := isHA || len(plan.ElasticsearchUrl.Value) > 0
+
+	conn, dialDiags := dialHost(ctx, plan, 300*time.Second)
+	diags.Append(dialDiags...)
+	if diags.HasError() {
+		return "", diags
+	}
+	defer conn.Close()
+
+	var haGroupId string
+	if isHA {
+		haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+		if err != nil {
+			addAPIError(&diags, "Error listing HA groups", "Could not list HA groups", err)
+			return "", diags
+		}
+		for _, group := range haGroups {
+			if group["name"].(string) == plan.HAGroupName.Value {
+				haGroupId = group["id"].(string)
+			}
+		}
+	}
+	err := r.buildInstaller(ctx, haGroupId)
+	if err != nil {
+		addAPIError(&diags, "Error creating host installer", "Could not create host installer", err)
+		return "", diags
+	}
+
+	installer, err := r.downloadInstaller(ctx, haGroupId)
+	if err != nil {
+		addAPIError(&diags, "Error downloading installer", "Could not download installer", err)
+		return "", diags
+	}
+	defer os.Remove(installer.Name())
+	defer installer.Close()
+	err = uploadFile(conn, installer, "/tmp/installer.sh", 0700)
+	if err != nil {
+		diags.AddError(
+			"Error copying installer",
+			"Could not copy installer to host: "+err.Error(),
+		)
+		return "", diags
+	}
+
+	args, argsDiags := installerArgs(ctx, plan, isHA, isElastic)
+	diags.Append(argsDiags...)
+	if diags.HasError() {
+		return "", diags
+	}
+	session, err := conn.NewSession()
+	if err != nil {
+		diags.AddError(
+			"Error creating ssh session",
+			"Could not create ssh session: "+err.Error(),
+		)
+		return "", diags
+	}
+	defer session.Close()
+	err = runCommand(ctx, session, "sudo /tmp/installer.sh -- "+strings.Join(args, " "))
+	if err != nil {
+		diags.AddError(
+			"Error running installer",
+			"Could not run installer: "+err.Error(),
+		)
+		return "", diags
+	}
+
+	installCtx, installCancel := withInstallationTimeout(ctx, plan)
+	defer installCancel()
+	var version types.String
+	err = poll(installCtx, time.Second, 10*time.Second, func() *resource.RetryError {
+		host, _, err := r.p.client.DefaultApi.GetHost(installCtx, plan.Name.Value).Execute()
+		if err != nil {
+			return resource.RetryableError(err)
+		}
+		version = hostVersion(host)
+		if !sameVersion(version.Value, plan.InstalledVersion.Value) {
+			return resource.RetryableError(fmt.Errorf("host reports version %q", version.Value))
+		}
+		return nil
+	})
+	if err != nil {
+		diags.AddError(
+			"Error upgrading host",
+			fmt.Sprintf("Host did not report version %s before timeout: %s", plan.InstalledVersion.Value, err),
+		)
+		return "", diags
+	}
+	return version.Value, diags
+}
+
+// waitForHost polls the main server until the host has registered with it, returning the host's details.
+func waitForHost(ctx context.Context, client *openapi.APIClient, name string) (map[string]interface{}, error) {
+	var host map[string]interface{}
+	err := poll(ctx, time.Second, 10*time.Second, func() *resource.RetryError {
+		var err error
+		var httpResponse *http.Response
+		host, httpResponse, err = client.DefaultApi.GetHost(ctx, name).Execute()
+		if err != nil && !isNotFound(httpResponse, err) {
+			return resource.NonRetryableError(err)
+		}
+		if hostGroupId, _ := host["hostGroupId"].(string); hostGroupId == "" {
+			return resource.RetryableError(fmt.Errorf("host %s has not registered yet", name))
+		}
+		return nil
+	})
+	return host, err
+}
+
+// withInstallationTimeout limits the wait for the host to come back to the main server after running the installer to
+// installation_timeout, when it is set.
+func withInstallationTimeout(ctx context.Context, plan Host) (context.Context, context.CancelFunc) {
+	if plan.InstallationTimeout.Null {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(plan.InstallationTimeout.Value)*time.Second)
+}
+
+// hostVersion returns the server version a host reports to the main server, or null if it doesn't report one
+func hostVersion(host map[string]interface{}) types.String {
+	if version, ok := host["productVersion"].(string); ok && len(version) > 0 {
+		return types.String{Value: version}
+	}
+	return types.String{Null: true}
+}
+
+// drain moves the accounts served by a host to drain_to_host_group if the host is the last one in its host group. If
+// no group to drain to is given, the destroy is refused unless force_destroy is set. gone is true if the host no
+// longer exists, in which case there is nothing to drain or uninstall.
+func (r resourceHost) drain(ctx context.Context, state Host) (gone bool, diags diag.Diagnostics) {
+	host, httpResponse, err := r.p.client.DefaultApi.GetHost(ctx, state.Name.Value).Execute()
+	if isNotFound(httpResponse, err) {
+		return true, diags
+	}
+	if err != nil {
+		addAPIError(&diags, "Error getting host", "Could not get host", err)
+		return false, diags
+	}
+	if host == nil {
+		return true, diags
+	}
+	hostGroupId, _ := host["hostGroupId"].(string)
+
+	hosts, _, err := r.p.client.DefaultApi.ListHosts(ctx).Execute()
+	if err != nil {
+		addAPIError(&diags, "Error listing hosts", "Could not list hosts", err)
+		return false, diags
+	}
+	for _, h := range hosts {
+		if h["hostGroupId"] == hostGroupId && h["id"] != host["id"] {
+			// another host in the group keeps serving its accounts
+			return false, diags
+		}
+	}
+
+	accounts, _, err := r.p.client.DefaultApi.ListAccounts(ctx).Execute()
+	if err != nil {
+		addAPIError(&diags, "Error getting accounts", "Could not read accounts", err)
+		return false, diags
+	}
+	var accountNames []string
+	for _, account := range accounts {
+		if account["hostGroupId"] == hostGroupId {
+			accountNames = append(accountNames, account["name"].(string))
+		}
+	}
+	if len(accountNames) == 0 {
+		return false, diags
+	}
+
+	if state.DrainToHostGroup.Null || len(state.DrainToHostGroup.Value) == 0 {
+		if state.ForceDestroy.Value {
+			diags.AddWarning(
+				"Destroying host with accounts",
+				fmt.Sprintf("force_destroy is set, so %s is being destroyed while it is the only host for accounts %s.", state.Name.Value, strings.Join(accountNames, ", ")),
+			)
+			return false, diags
+		}
+		diags.AddError(
+			"Host has accounts",
+			fmt.Sprintf("%s is the only host for accounts %s. Set drain_to_host_group to move them to another host group first, or set force_destroy to destroy the host anyway.", state.Name.Value, strings.Join(accountNames, ", ")),
+		)
+		return false, diags
+	}
+
+	haGroups, _, err := r.p.client.DefaultApi.ListHAGroups(ctx).Execute()
+	if err != nil {
+		addAPIError(&diags, "Error listing HA groups", "Could not read HA groups", err)
+		return false, diags
+	}
+	var targetHostGroupId string
+	for _, group := range haGroups {
+		if group["name"].(string) == state.DrainToHostGroup.Value {
+			targetHostGroupId = group["id"].(string)
+			break
+		}
+	}
+	if len(targetHostGroupId) == 0 || targetHostGroupId == hostGroupId {
+		diags.AddAttributeError(
+			path.Root("drain_to_host_group"),
+			"Invalid host group",
+			"Could not find another host group named "+state.DrainToHostGroup.Value+" to move accounts to.",
+		)
+		return false, diags
+	}
+	for _, accountName := range accountNames {
+		logDebug(ctx, "Moving account", map[string]interface{}{"account": accountName, "host_group": state.DrainToHostGroup.Value})
+		_, _, err = r.p.client.DefaultApi.UpdateAccountHost(ctx, accountName, targetHostGroupId).Execute()
+		if err != nil {
+			addAPIAttributeError(&diags, path.Root("drain_to_host_group"), "Error updating account host", "Could not update account host for "+accountName, err)
+			return false, diags
+		}
+	}
+	return false, diags
+}
@@ -0,0 +1,554 @@
+package xsoar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// incidentFieldTypes are the types a custom incident or indicator field can have
+var incidentFieldTypes = []string{
+	"attachments",
+	"boolean",
+	"date",
+	"grid",
+	"html",
+	"longText",
+	"markdown",
+	"multiSelect",
+	"number",
+	"role",
+	"shortText",
+	"singleSelect",
+	"tagsSelect",
+	"timer",
+	"url",
+	"user",
+}
+
+type isValidFieldType struct{}
+
+func (v isValidFieldType) Description(ctx context.Context) string {
+	return fmt.Sprintf("type must be one of %s exactly", strings.Join(incidentFieldTypes, ", "))
+}
+
+func (v isValidFieldType) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("type must be one of `%s` exactly", strings.Join(incidentFieldTypes, "`, `"))
+}
+
+func (v isValidFieldType) Validate(ctx context.Context, request tfsdk.ValidateAttributeRequest, response *tfsdk.ValidateAttributeResponse) {
+	var str types.String
+	diags := tfsdk.ValueAs(ctx, request.AttributeConfig, &str)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() || str.Null || str.Unknown {
+		return
+	}
+	for _, fieldType := range incidentFieldTypes {
+		if str.Value == fieldType {
+			return
+		}
+	}
+	response.Diagnostics.AddAttributeError(
+		request.AttributePath,
+		"Invalid Field Type Value",
+		fmt.Sprintf("Type must be one of %s exactly, got: %s.", strings.Join(incidentFieldTypes, ", "), str.Value),
+	)
+}
+
+// cliNamePattern is the form of the machine name of a field, which scripts and mappers use to refer to it
+var cliNamePattern = regexp.MustCompile(`^[a-z0-9]+$`)
+
+type isValidCliName struct{}
+
+func (v isValidCliName) Description(ctx context.Context) string {
+	return fmt.Sprint("cli_name must only contain lowercase letters and digits")
+}
+
+func (v isValidCliName) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprint("cli_name must only contain lowercase letters and digits")
+}
+
+func (v isValidCliName) Validate(ctx context.Context, request tfsdk.ValidateAttributeRequest, response *tfsdk.ValidateAttributeResponse) {
+	var str types.String
+	diags := tfsdk.ValueAs(ctx, request.AttributeConfig, &str)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() || str.Null || str.Unknown {
+		return
+	}
+	if !cliNamePattern.MatchString(str.Value) {
+		response.Diagnostics.AddAttributeError(
+			request.AttributePath,
+			"Invalid CLI Name Value",
+			fmt.Sprintf("CLI name must only contain lowercase letters and digits, got: %s.", str.Value),
+		)
+	}
+}
+
+type resourceIncidentFieldType struct{}
+
+// GetSchema Resource schema
+func (r resourceIncidentFieldType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:     types.StringType,
+				Required: true,
+			},
+			"id": {
+				Type:     types.StringType,
+				Computed: true,
+				Optional: false,
+			},
+			"cli_name": {
+				Type:          types.StringType,
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+				Validators:    []tfsdk.AttributeValidator{isValidCliName{}},
+			},
+			"type": {
+				Type:          types.StringType,
+				Required:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+				Validators:    []tfsdk.AttributeValidator{isValidFieldType{}},
+			},
+			"description": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"select_values": {
+				Type:     types.ListType{ElemType: types.StringType},
+				Optional: true,
+			},
+			"grid_columns": {
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+			},
+			"associated_types": {
+				Type:     types.SetType{ElemType: types.StringType},
+				Optional: true,
+				Computed: true,
+			},
+			"associated_to_all": {
+				Type:     types.BoolType,
+				Optional: true,
+				Computed: true,
+			},
+			"required": {
+				Type:     types.BoolType,
+				Optional: true,
+				Computed: true,
+			},
+			"system": {
+				Type:     types.BoolType,
+				Computed: true,
+			},
+			"propagation_labels": {
+				Type:     types.SetType{ElemType: types.StringType},
+				Optional: true,
+				Computed: true,
+			},
+			"account": {
+				Type:          types.StringType,
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
+	}, nil
+}
+
+// NewResource instance
+func (r resourceIncidentFieldType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceField{
+		p:     *(p.(*provider)),
+		group: incidentFields,
+	}, nil
+}
+
+// fieldGroup is the kind of object fields belong to. Incident and indicator fields share an API, and are told apart by
+// the group they are saved with.
+type fieldGroup struct {
+	// id is the group the API saves the fields with
+	id int64
+	// name is what the fields are called in diagnostics
+	name string
+	// resource is the type of the resource managing the fields, and its logging subsystem
+	resource string
+}
+
+var (
+	incidentFields  = fieldGroup{id: 0, name: "incident field", resource: "xsoar_incident_field"}
+	indicatorFields = fieldGroup{id: 2, name: "indicator field", resource: "xsoar_indicator_field"}
+)
+
+type resourceField struct {
+	p     provider
+	group fieldGroup
+}
+
+// Create a new resource
+func (r resourceField) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, r.group.resource)
+	if !r.p.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource. This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
+		)
+		return
+	}
+
+	// Retrieve values from plan
+	var plan IncidentField
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 5*time.Minute)
+	defer cancel()
+
+	// Create
+	fieldRequest := map[string]interface{}{
+		"group":   r.group.id,
+		"type":    plan.Type.Value,
+		"content": true,
+	}
+	if !plan.CliName.Unknown && !plan.CliName.Null {
+		fieldRequest["cliName"] = plan.CliName.Value
+	}
+	resp.Diagnostics.Append(setFieldRequest(ctx, plan, fieldRequest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var field map[string]interface{}
+	_, err := doRequest(ctx, r.p.client, http.MethodPost, r.p.accountPrefix(plan.Account)+"/incidentfield", fieldRequest, &field)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error creating "+r.group.name, "Could not create "+r.group.name, err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := fieldResult(field, plan.GridColumns)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourceField) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, r.group.resource)
+	// Get current state
+	var state IncidentField
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
+
+	// Get resource from API
+	field, err := getField(ctx, r.p.client, r.p.accountPrefix(state.Account), r.group, state.Id.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting "+r.group.name, "Could not get "+r.group.name, err)
+		return
+	}
+	if field == nil {
+		logDebug(ctx, "Field not found, removing from state")
+		// Remove resource from state
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := fieldResult(field, state.GridColumns)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = state.Account
+	result.Timeouts = state.Timeouts
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource
+func (r resourceField) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, r.group.resource)
+	// Get plan values
+	var plan IncidentField
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 5*time.Minute)
+	defer cancel()
+
+	// Get current state
+	var state IncidentField
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The whole field is saved at once, so start from the current one to keep the attributes not in the plan
+	prefix := r.p.accountPrefix(plan.Account)
+	fieldRequest, err := getField(ctx, r.p.client, prefix, r.group, state.Id.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting "+r.group.name, "Could not get "+r.group.name, err)
+		return
+	}
+	if fieldRequest == nil {
+		resp.Diagnostics.AddError(
+			"Error updating "+r.group.name,
+			"Could not find "+r.group.name+" "+state.Id.Value,
+		)
+		return
+	}
+	resp.Diagnostics.Append(setFieldRequest(ctx, plan, fieldRequest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var field map[string]interface{}
+	_, err = doRequest(ctx, r.p.client, http.MethodPost, prefix+"/incidentfield", fieldRequest, &field)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error updating "+r.group.name, "Could not update "+r.group.name, err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := fieldResult(field, plan.GridColumns)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourceField) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, r.group.resource)
+	// Get state
+	var state IncidentField
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
+
+	// Delete
+	httpResponse, err := doRequest(ctx, r.p.client, http.MethodDelete, r.p.accountPrefix(state.Account)+"/incidentfield/"+state.Id.Value, nil, nil)
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting "+r.group.name, "Could not delete "+r.group.name, err)
+		return
+	}
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
+
+func (r resourceField) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, r.group.resource)
+	var diags diag.Diagnostics
+	accname := r.p.splitImportId(req.ID)
+	var acc, name, prefix string
+	if len(accname) == 1 {
+		name = req.ID
+	} else {
+		acc, name = accname[0], accname[1]
+		prefix = "/acc_" + acc
+	}
+	field, err := getField(ctx, r.p.client, prefix, r.group, name)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error importing "+r.group.name, "Could not import "+r.group.name, err)
+		return
+	}
+	if field == nil {
+		resp.Diagnostics.AddError(
+			"Error importing "+r.group.name,
+			"Could not find "+r.group.name+" "+name,
+		)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := fieldResult(field, types.String{Null: true})
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if len(accname) == 1 {
+		result.Account = types.String{Null: true}
+	} else {
+		result.Account = types.String{Value: acc}
+	}
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// ModifyPlan plans the account, checking it is only given when the provider is managing a multi-tenant main host and
+// falling back to the provider's default account
+func (r resourceField) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, r.group.resource)
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	r.p.planAccount(ctx, req, resp)
+}
+
+// getField finds a field of the group by ID, CLI name or name among those of the main tenant, or of the account prefix
+// addresses, returning nil if there is none. The API has no endpoint for a single field.
+func getField(ctx context.Context, client *openapi.APIClient, prefix string, group fieldGroup, identifier string) (map[string]interface{}, error) {
+	var fields []map[string]interface{}
+	_, err := doRequest(ctx, client, http.MethodGet, prefix+"/incidentfields", nil, &fields)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		if int64Value(field["group"]) != group.id {
+			continue
+		}
+		if field["id"] == identifier || field["cliName"] == identifier || field["name"] == identifier {
+			return field, nil
+		}
+	}
+	return nil, nil
+}
+
+// setFieldRequest sets the attributes of the plan that are known on the field sent to the API
+func setFieldRequest(ctx context.Context, plan IncidentField, field map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	field["name"] = plan.Name.Value
+	field["description"] = plan.Description.Value
+	var selectValues []string
+	if !plan.SelectValues.Unknown && !plan.SelectValues.Null {
+		diags.Append(plan.SelectValues.ElementsAs(ctx, &selectValues, false)...)
+	}
+	field["selectValues"] = selectValues
+	if !plan.GridColumns.Unknown && !plan.GridColumns.Null {
+		var columns []interface{}
+		err := json.Unmarshal([]byte(plan.GridColumns.Value), &columns)
+		if err != nil {
+			diags.AddError(
+				"Error unmarshalling json",
+				"Could not unmarshal grid_columns json: "+err.Error(),
+			)
+			return diags
+		}
+		field["columns"] = columns
+	}
+	if !plan.AssociatedTypes.Unknown && !plan.AssociatedTypes.Null {
+		var associatedTypes []string
+		diags.Append(plan.AssociatedTypes.ElementsAs(ctx, &associatedTypes, false)...)
+		field["associatedTypes"] = associatedTypes
+	}
+	if !plan.AssociatedToAll.Unknown && !plan.AssociatedToAll.Null {
+		field["associatedToAll"] = plan.AssociatedToAll.Value
+	}
+	if !plan.Required.Unknown && !plan.Required.Null {
+		field["required"] = plan.Required.Value
+	}
+	if !plan.PropagationLabels.Unknown && !plan.PropagationLabels.Null {
+		var propLabels []string
+		diags.Append(plan.PropagationLabels.ElementsAs(ctx, &propLabels, false)...)
+		field["propagationLabels"] = propLabels
+	}
+	return diags
+}
+
+// fieldResult maps a field returned by the API to the resource schema, leaving the account and timeouts to the
+// caller. The server adds attributes to grid columns, so they are cut down to the shape of priorGridColumns, the
+// grid_columns last planned or stored.
+func fieldResult(field map[string]interface{}, priorGridColumns types.String) (IncidentField, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result := IncidentField{
+		Name:              types.String{Value: stringValue(field["name"])},
+		Id:                types.String{Value: stringValue(field["id"])},
+		CliName:           types.String{Value: stringValue(field["cliName"])},
+		Type:              types.String{Value: stringValue(field["type"])},
+		Description:       optionalString(field["description"]),
+		AssociatedTypes:   stringSet(field["associatedTypes"]),
+		PropagationLabels: stringSet(field["propagationLabels"]),
+	}
+	associatedToAll, _ := field["associatedToAll"].(bool)
+	result.AssociatedToAll = types.Bool{Value: associatedToAll}
+	required, _ := field["required"].(bool)
+	result.Required = types.Bool{Value: required}
+	system, _ := field["system"].(bool)
+	result.System = types.Bool{Value: system}
+
+	// fields without select values are given an empty list, which is left out of the state
+	var selectValues []attr.Value
+	for _, value := range listOrEmpty(field["selectValues"]) {
+		if s, ok := value.(string); ok {
+			selectValues = append(selectValues, types.String{Value: s})
+		}
+	}
+	if len(selectValues) == 0 {
+		result.SelectValues = types.List{Null: true, ElemType: types.StringType}
+	} else {
+		result.SelectValues = types.List{Elems: selectValues, ElemType: types.StringType}
+	}
+
+	if columns := listOrEmpty(field["columns"]); len(columns) > 0 {
+		gridColumns, err := jsonLike(columns, priorGridColumns)
+		if err != nil {
+			diags.AddError(
+				"Error marshalling json",
+				"Could not marshal json: "+err.Error(),
+			)
+			return result, diags
+		}
+		result.GridColumns = gridColumns
+	} else {
+		result.GridColumns = types.String{Null: true}
+	}
+	return result, diags
+}
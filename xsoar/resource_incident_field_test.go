@@ -0,0 +1,182 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAccIncidentField_basic(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccIncidentFieldResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckIncidentFieldResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccIncidentFieldResourceBasic(rName),
+				Check:  testAccCheckIncidentFieldResourceExists(rName),
+			},
+			{
+				ResourceName:      "xsoar_incident_field." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccIncidentFieldResourceUpdated(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIncidentFieldResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_incident_field."+rName, "select_values.#", "3"),
+					resource.TestCheckResourceAttr("xsoar_incident_field."+rName, "description", "Severity reported by the source"),
+					resource.TestCheckResourceAttr("xsoar_incident_field."+rName, "required", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIncidentField_gridColumns(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccIncidentFieldResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckIncidentFieldResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				// the server fills in the attributes left out of each column, which must not show up as a change
+				Config: testAccIncidentFieldResourceGrid(rName, `{ key = "host", displayName = "Host", type = "shortText" }`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIncidentFieldResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_incident_field."+rName, "grid_columns", `[{"displayName":"Host","key":"host","type":"shortText"}]`),
+				),
+			},
+			{
+				ResourceName:      "xsoar_incident_field." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// an imported field has nothing to cut the server's additions down to
+				ImportStateVerifyIgnore: []string{"grid_columns"},
+			},
+			{
+				Config: testAccIncidentFieldResourceGrid(rName, `{ key = "host", displayName = "Host", type = "shortText" }, { key = "ip", displayName = "IP", type = "shortText", width = 100 }`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIncidentFieldResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_incident_field."+rName, "grid_columns", `[{"displayName":"Host","key":"host","type":"shortText"},{"displayName":"IP","key":"ip","type":"shortText","width":100}]`),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIncidentField_invalidCliName(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccIncidentFieldResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "xsoar_incident_field" "` + rName + `" {
+  name     = "` + rName + `"
+  cli_name = "source_ip"
+  type     = "shortText"
+}`,
+				ExpectError: regexp.MustCompile("Invalid CLI Name Value"),
+			},
+		},
+	})
+}
+
+func testAccIncidentFieldResourcePreCheck(t *testing.T) {}
+
+func testAccCheckIncidentFieldResourceExists(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources["xsoar_incident_field."+r]
+		if !ok {
+			return fmt.Errorf("not found: %s in %s", r, state.RootModule().Resources)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		field, err := getField(context.Background(), openapiClient, "", incidentFields, rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting incident field: " + err.Error())
+		}
+		if field == nil {
+			return fmt.Errorf("incident field " + rs.Primary.ID + " not found")
+		}
+		return nil
+	}
+}
+
+func testAccCheckIncidentFieldResourceDestroy(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		field, err := getField(context.Background(), openapiClient, "", incidentFields, r)
+		if err != nil {
+			return fmt.Errorf("Error getting incident field: " + err.Error())
+		}
+		if field != nil {
+			return fmt.Errorf("found incident field when none was expected")
+		}
+		return nil
+	}
+}
+
+func testAccIncidentFieldResourceBasic(name string) string {
+	c := `
+resource "xsoar_incident_field" "{name}" {
+  name          = "{name}"
+  type          = "singleSelect"
+  select_values = ["low", "high"]
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccIncidentFieldResourceUpdated(name string) string {
+	c := `
+resource "xsoar_incident_field" "{name}" {
+  name          = "{name}"
+  type          = "singleSelect"
+  select_values = ["low", "medium", "high"]
+  description   = "Severity reported by the source"
+  required      = true
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccIncidentFieldResourceGrid(name string, columns string) string {
+	c := `
+resource "xsoar_incident_field" "{name}" {
+  name         = "{name}"
+  type         = "grid"
+  grid_columns = jsonencode([{columns}])
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	c = strings.Replace(c, "{columns}", columns, -1)
+	return c
+}
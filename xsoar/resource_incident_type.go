@@ -0,0 +1,465 @@
+package xsoar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net/http"
+	"time"
+)
+
+type isValidAutoExtractMode struct{}
+
+func (v isValidAutoExtractMode) Description(ctx context.Context) string {
+	return fmt.Sprint("auto_extract_mode must be All, Specific or None exactly")
+}
+
+func (v isValidAutoExtractMode) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprint("auto_extract_mode must be `All`, `Specific` or `None` exactly")
+}
+
+func (v isValidAutoExtractMode) Validate(ctx context.Context, request tfsdk.ValidateAttributeRequest, response *tfsdk.ValidateAttributeResponse) {
+	var str types.String
+	diags := tfsdk.ValueAs(ctx, request.AttributeConfig, &str)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() || str.Null || str.Unknown {
+		return
+	}
+	if !(str.Value == "All" || str.Value == "Specific" || str.Value == "None") {
+		response.Diagnostics.AddAttributeError(
+			request.AttributePath,
+			"Invalid Auto Extract Mode Value",
+			fmt.Sprintf("Auto extract mode must be one of All, Specific or None exactly, got: %s.", str.Value),
+		)
+
+		return
+	}
+}
+
+type resourceIncidentTypeType struct{}
+
+// GetSchema Resource schema
+func (r resourceIncidentTypeType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:          types.StringType,
+				Required:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"id": {
+				Type:     types.StringType,
+				Computed: true,
+				Optional: false,
+			},
+			"color": {
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+			},
+			"playbook": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"autorun": {
+				Type:     types.BoolType,
+				Optional: true,
+				Computed: true,
+			},
+			"layout": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"sla": {
+				Type:     types.Int64Type,
+				Optional: true,
+				Computed: true,
+			},
+			"sla_reminder": {
+				Type:     types.Int64Type,
+				Optional: true,
+				Computed: true,
+			},
+			"auto_extract_mode": {
+				Type:       types.StringType,
+				Optional:   true,
+				Computed:   true,
+				Validators: []tfsdk.AttributeValidator{isValidAutoExtractMode{}},
+			},
+			"auto_extract_fields": {
+				Type:     types.StringType,
+				Optional: true,
+				Computed: true,
+			},
+			"propagation_labels": {
+				Type:     types.SetType{ElemType: types.StringType},
+				Optional: true,
+				Computed: true,
+			},
+			"account": {
+				Type:          types.StringType,
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
+	}, nil
+}
+
+// NewResource instance
+func (r resourceIncidentTypeType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceIncidentType{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+type resourceIncidentType struct {
+	p provider
+}
+
+// Create a new resource
+func (r resourceIncidentType) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_incident_type")
+	if !r.p.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource. This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
+		)
+		return
+	}
+
+	// Retrieve values from plan
+	var plan IncidentType
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 5*time.Minute)
+	defer cancel()
+
+	// Create
+	incidentTypeRequest := map[string]interface{}{}
+	resp.Diagnostics.Append(setIncidentTypeRequest(ctx, plan, incidentTypeRequest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var incidentType map[string]interface{}
+	_, err := doRequest(ctx, r.p.client, http.MethodPost, r.p.accountPrefix(plan.Account)+"/incidenttype", incidentTypeRequest, &incidentType)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error creating incident type", "Could not create incident type", err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := incidentTypeResult(incidentType, plan.AutoExtractFields)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourceIncidentType) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_incident_type")
+	// Get current state
+	var state IncidentType
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
+
+	// Get resource from API
+	incidentType, err := getIncidentType(ctx, r.p.client, r.p.accountPrefix(state.Account), state.Id.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting incident type", "Could not get incident type", err)
+		return
+	}
+	if incidentType == nil {
+		logDebug(ctx, "Incident type not found, removing from state")
+		// Remove resource from state
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := incidentTypeResult(incidentType, state.AutoExtractFields)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = state.Account
+	result.Timeouts = state.Timeouts
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource
+func (r resourceIncidentType) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_incident_type")
+	// Get plan values
+	var plan IncidentType
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 5*time.Minute)
+	defer cancel()
+
+	// Get current state
+	var state IncidentType
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The whole incident type is saved at once, so start from the current one to keep the attributes not in the plan
+	prefix := r.p.accountPrefix(plan.Account)
+	incidentTypeRequest, err := getIncidentType(ctx, r.p.client, prefix, state.Id.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting incident type", "Could not get incident type", err)
+		return
+	}
+	if incidentTypeRequest == nil {
+		resp.Diagnostics.AddError(
+			"Error updating incident type",
+			"Could not find incident type "+state.Id.Value,
+		)
+		return
+	}
+	resp.Diagnostics.Append(setIncidentTypeRequest(ctx, plan, incidentTypeRequest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var incidentType map[string]interface{}
+	_, err = doRequest(ctx, r.p.client, http.MethodPost, prefix+"/incidenttype", incidentTypeRequest, &incidentType)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error updating incident type", "Could not update incident type", err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := incidentTypeResult(incidentType, plan.AutoExtractFields)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourceIncidentType) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_incident_type")
+	// Get state
+	var state IncidentType
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
+
+	// Delete
+	httpResponse, err := doRequest(ctx, r.p.client, http.MethodPost, r.p.accountPrefix(state.Account)+"/incidenttype/delete", map[string]interface{}{"id": state.Id.Value}, nil)
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting incident type", "Could not delete incident type", err)
+		return
+	}
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
+
+func (r resourceIncidentType) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_incident_type")
+	var diags diag.Diagnostics
+	accname := r.p.splitImportId(req.ID)
+	var acc, name, prefix string
+	if len(accname) == 1 {
+		name = req.ID
+	} else {
+		acc, name = accname[0], accname[1]
+		prefix = "/acc_" + acc
+	}
+	incidentType, err := getIncidentType(ctx, r.p.client, prefix, name)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error importing incident type", "Could not import incident type", err)
+		return
+	}
+	if incidentType == nil {
+		resp.Diagnostics.AddError(
+			"Error importing incident type",
+			"Could not find incident type "+name,
+		)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := incidentTypeResult(incidentType, types.String{Null: true})
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if len(accname) == 1 {
+		result.Account = types.String{Null: true}
+	} else {
+		result.Account = types.String{Value: acc}
+	}
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// ModifyPlan plans the account, checking it is only given when the provider is managing a multi-tenant main host and
+// falling back to the provider's default account
+func (r resourceIncidentType) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_incident_type")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	r.p.planAccount(ctx, req, resp)
+}
+
+// getIncidentType finds an incident type by ID or name among those of the main tenant, or of the account prefix
+// addresses, returning nil if there is none. The API has no endpoint for a single incident type.
+func getIncidentType(ctx context.Context, client *openapi.APIClient, prefix string, identifier string) (map[string]interface{}, error) {
+	var incidentTypes []map[string]interface{}
+	_, err := doRequest(ctx, client, http.MethodGet, prefix+"/incidenttype", nil, &incidentTypes)
+	if err != nil {
+		return nil, err
+	}
+	for _, incidentType := range incidentTypes {
+		if incidentType["id"] == identifier || incidentType["name"] == identifier {
+			return incidentType, nil
+		}
+	}
+	return nil, nil
+}
+
+// setIncidentTypeRequest sets the attributes of the plan that are known on the incident type sent to the API
+func setIncidentTypeRequest(ctx context.Context, plan IncidentType, incidentType map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	incidentType["name"] = plan.Name.Value
+	if !plan.Color.Unknown && !plan.Color.Null {
+		incidentType["color"] = plan.Color.Value
+	}
+	if !plan.Playbook.Unknown {
+		incidentType["playbookId"] = plan.Playbook.Value
+	}
+	if !plan.Autorun.Unknown && !plan.Autorun.Null {
+		incidentType["autorun"] = plan.Autorun.Value
+	}
+	if !plan.Layout.Unknown {
+		incidentType["layout"] = plan.Layout.Value
+	}
+	if !plan.Sla.Unknown && !plan.Sla.Null {
+		incidentType["sla"] = plan.Sla.Value
+	}
+	if !plan.SlaReminder.Unknown && !plan.SlaReminder.Null {
+		incidentType["slaReminder"] = plan.SlaReminder.Value
+	}
+	extractSettings, _ := incidentType["extractSettings"].(map[string]interface{})
+	if extractSettings == nil {
+		extractSettings = map[string]interface{}{}
+	}
+	if !plan.AutoExtractMode.Unknown && !plan.AutoExtractMode.Null {
+		extractSettings["mode"] = plan.AutoExtractMode.Value
+	}
+	if !plan.AutoExtractFields.Unknown && !plan.AutoExtractFields.Null {
+		var fields map[string]interface{}
+		err := json.Unmarshal([]byte(plan.AutoExtractFields.Value), &fields)
+		if err != nil {
+			diags.AddError(
+				"Error unmarshalling json",
+				"Could not unmarshal auto_extract_fields json: "+err.Error(),
+			)
+			return diags
+		}
+		extractSettings["fieldCliNameToExtractSettings"] = fields
+	}
+	if len(extractSettings) > 0 {
+		incidentType["extractSettings"] = extractSettings
+	}
+	if !plan.PropagationLabels.Unknown && !plan.PropagationLabels.Null {
+		var propLabels []string
+		diags.Append(plan.PropagationLabels.ElementsAs(ctx, &propLabels, false)...)
+		incidentType["propagationLabels"] = propLabels
+	}
+	return diags
+}
+
+// incidentTypeResult maps an incident type returned by the API to the resource schema, leaving the account and
+// timeouts to the caller. The auto extract fields are kept as they were written in priorFields when the server's only
+// differ in formatting or in settings the server fills in.
+func incidentTypeResult(incidentType map[string]interface{}, priorFields types.String) (IncidentType, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result := IncidentType{
+		Name:              types.String{Value: stringValue(incidentType["name"])},
+		Id:                types.String{Value: stringValue(incidentType["id"])},
+		Color:             optionalString(incidentType["color"]),
+		Playbook:          optionalString(incidentType["playbookId"]),
+		Layout:            optionalString(incidentType["layout"]),
+		Sla:               types.Int64{Value: int64Value(incidentType["sla"])},
+		SlaReminder:       types.Int64{Value: int64Value(incidentType["slaReminder"])},
+		PropagationLabels: stringSet(incidentType["propagationLabels"]),
+	}
+	autorun, _ := incidentType["autorun"].(bool)
+	result.Autorun = types.Bool{Value: autorun}
+
+	extractSettings, _ := incidentType["extractSettings"].(map[string]interface{})
+	result.AutoExtractMode = optionalString(extractSettings["mode"])
+	if fields, ok := extractSettings["fieldCliNameToExtractSettings"].(map[string]interface{}); ok {
+		fieldsJson, err := jsonLike(fields, priorFields)
+		if err != nil {
+			diags.AddError(
+				"Error marshalling json",
+				"Could not marshal json: "+err.Error(),
+			)
+			return result, diags
+		}
+		result.AutoExtractFields = fieldsJson
+	} else {
+		result.AutoExtractFields = types.String{Null: true}
+	}
+	return result, diags
+}
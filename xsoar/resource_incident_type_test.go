@@ -0,0 +1,171 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAccIncidentType_basic(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccIncidentTypeResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckIncidentTypeResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccIncidentTypeResourceBasic(rName),
+				Check:  testAccCheckIncidentTypeResourceExists(rName),
+			},
+			{
+				ResourceName:      "xsoar_incident_type." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccIncidentTypeResourceUpdated(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIncidentTypeResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_incident_type."+rName, "color", "#FF0000"),
+					resource.TestCheckResourceAttr("xsoar_incident_type."+rName, "sla", "120"),
+					resource.TestCheckResourceAttr("xsoar_incident_type."+rName, "auto_extract_mode", "None"),
+				),
+			},
+			{
+				// an incident type deleted outside of Terraform should be planned to be created again
+				PreConfig: func() {
+					_, err := doRequest(context.Background(), openapiClient, http.MethodPost, "/incidenttype/delete", map[string]interface{}{"id": rName}, nil)
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccIncidentTypeResourceUpdated(rName),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccIncidentType_autoExtractFields(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccIncidentTypeResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckIncidentTypeResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				// the server fills in the settings left out of each field, which must not show up as a change
+				Config: testAccIncidentTypeResourceAutoExtract(rName, `["IP"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIncidentTypeResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_incident_type."+rName, "auto_extract_fields", `{"sourceip":{"extractIndicatorTypesIDs":["IP"]}}`),
+				),
+			},
+			{
+				ResourceName:      "xsoar_incident_type." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// an imported incident type has nothing to cut the server's additions down to
+				ImportStateVerifyIgnore: []string{"auto_extract_fields"},
+			},
+			{
+				Config: testAccIncidentTypeResourceAutoExtract(rName, `["IP", "Domain"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIncidentTypeResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_incident_type."+rName, "auto_extract_fields", `{"sourceip":{"extractIndicatorTypesIDs":["IP","Domain"]}}`),
+				),
+			},
+		},
+	})
+}
+
+func testAccIncidentTypeResourcePreCheck(t *testing.T) {}
+
+func testAccCheckIncidentTypeResourceExists(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources["xsoar_incident_type."+r]
+		if !ok {
+			return fmt.Errorf("not found: %s in %s", r, state.RootModule().Resources)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		incidentType, err := getIncidentType(context.Background(), openapiClient, "", rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting incident type: " + err.Error())
+		}
+		if incidentType == nil {
+			return fmt.Errorf("incident type " + rs.Primary.ID + " not found")
+		}
+		return nil
+	}
+}
+
+func testAccCheckIncidentTypeResourceDestroy(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		incidentType, err := getIncidentType(context.Background(), openapiClient, "", r)
+		if err != nil {
+			return fmt.Errorf("Error getting incident type: " + err.Error())
+		}
+		if incidentType != nil {
+			return fmt.Errorf("found incident type when none was expected")
+		}
+		return nil
+	}
+}
+
+func testAccIncidentTypeResourceBasic(name string) string {
+	c := `
+resource "xsoar_incident_type" "{name}" {
+  name = "{name}"
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccIncidentTypeResourceUpdated(name string) string {
+	c := `
+resource "xsoar_incident_type" "{name}" {
+  name              = "{name}"
+  color             = "#FF0000"
+  sla               = 120
+  sla_reminder      = 30
+  auto_extract_mode = "None"
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccIncidentTypeResourceAutoExtract(name string, indicatorTypes string) string {
+	c := `
+resource "xsoar_incident_type" "{name}" {
+  name              = "{name}"
+  auto_extract_mode = "Specific"
+  auto_extract_fields = jsonencode({
+    sourceip = { extractIndicatorTypesIDs = {indicatorTypes} }
+  })
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	c = strings.Replace(c, "{indicatorTypes}", indicatorTypes, -1)
+	return c
+}
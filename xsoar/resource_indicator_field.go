@@ -0,0 +1,23 @@
+package xsoar
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+type resourceIndicatorFieldType struct{}
+
+// GetSchema Resource schema
+func (r resourceIndicatorFieldType) GetSchema(ctx context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	// indicator fields have the same attributes as incident fields
+	return resourceIncidentFieldType{}.GetSchema(ctx)
+}
+
+// NewResource instance
+func (r resourceIndicatorFieldType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceField{
+		p:     *(p.(*provider)),
+		group: indicatorFields,
+	}, nil
+}
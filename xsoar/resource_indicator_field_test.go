@@ -0,0 +1,164 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"strings"
+	"testing"
+)
+
+func TestAccIndicatorField_basic(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccIndicatorFieldResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckIndicatorFieldResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccIndicatorFieldResourceBasic(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIndicatorFieldResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_indicator_field."+rName, "id", "indicator_"+strings.ToLower(rName)),
+				),
+			},
+			{
+				ResourceName:      "xsoar_indicator_field." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccIndicatorFieldResourceUpdated(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIndicatorFieldResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_indicator_field."+rName, "description", "Team owning the asset"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIndicatorField_sameNameAsIncidentField(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccIndicatorFieldResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckIndicatorFieldResourceDestroy(rName),
+			testAccCheckIncidentFieldResourceDestroy(rName),
+		),
+		Steps: []resource.TestStep{
+			{
+				// incident and indicator fields share an API, and are kept apart by their group
+				Config: testAccIndicatorFieldResourceWithIncidentField(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIndicatorFieldResourceExists(rName),
+					testAccCheckIncidentFieldResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_indicator_field."+rName, "id", "indicator_"+strings.ToLower(rName)),
+					resource.TestCheckResourceAttr("xsoar_incident_field."+rName, "id", "incident_"+strings.ToLower(rName)),
+					resource.TestCheckResourceAttr("xsoar_indicator_field."+rName, "type", "shortText"),
+					resource.TestCheckResourceAttr("xsoar_incident_field."+rName, "type", "longText"),
+				),
+			},
+			{
+				// importing by name finds the field of the resource's own group
+				ResourceName:      "xsoar_indicator_field." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "xsoar_incident_field." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccIndicatorFieldResourcePreCheck(t *testing.T) {}
+
+func testAccCheckIndicatorFieldResourceExists(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources["xsoar_indicator_field."+r]
+		if !ok {
+			return fmt.Errorf("not found: %s in %s", r, state.RootModule().Resources)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		field, err := getField(context.Background(), openapiClient, "", indicatorFields, rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting indicator field: " + err.Error())
+		}
+		if field == nil {
+			return fmt.Errorf("indicator field " + rs.Primary.ID + " not found")
+		}
+		return nil
+	}
+}
+
+func testAccCheckIndicatorFieldResourceDestroy(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		field, err := getField(context.Background(), openapiClient, "", indicatorFields, r)
+		if err != nil {
+			return fmt.Errorf("Error getting indicator field: " + err.Error())
+		}
+		if field != nil {
+			return fmt.Errorf("found indicator field when none was expected")
+		}
+		return nil
+	}
+}
+
+func testAccIndicatorFieldResourceBasic(name string) string {
+	c := `
+resource "xsoar_indicator_field" "{name}" {
+  name = "{name}"
+  type = "shortText"
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccIndicatorFieldResourceUpdated(name string) string {
+	c := `
+resource "xsoar_indicator_field" "{name}" {
+  name        = "{name}"
+  type        = "shortText"
+  description = "Team owning the asset"
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccIndicatorFieldResourceWithIncidentField(name string) string {
+	c := `
+resource "xsoar_indicator_field" "{name}" {
+  name = "{name}"
+  type = "shortText"
+}
+
+resource "xsoar_incident_field" "{name}" {
+  name = "{name}"
+  type = "longText"
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
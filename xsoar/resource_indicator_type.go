@@ -0,0 +1,411 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// isValidExpiration checks an expiration is a number of minutes, or 0 for indicators that never expire
+type isValidExpiration struct{}
+
+func (v isValidExpiration) Description(ctx context.Context) string {
+	return fmt.Sprint("expiration must be a number of minutes, or 0 to never expire")
+}
+
+func (v isValidExpiration) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprint("expiration must be a number of minutes, or `0` to never expire")
+}
+
+func (v isValidExpiration) Validate(ctx context.Context, request tfsdk.ValidateAttributeRequest, response *tfsdk.ValidateAttributeResponse) {
+	var minutes types.Int64
+	diags := tfsdk.ValueAs(ctx, request.AttributeConfig, &minutes)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() || minutes.Null || minutes.Unknown {
+		return
+	}
+	if minutes.Value < 0 {
+		response.Diagnostics.AddAttributeError(
+			request.AttributePath,
+			"Invalid Expiration Value",
+			fmt.Sprintf("Expiration must be a number of minutes, or 0 for indicators that never expire, got: %d.", minutes.Value),
+		)
+	}
+}
+
+type resourceIndicatorTypeType struct{}
+
+// GetSchema Resource schema
+func (r resourceIndicatorTypeType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:          types.StringType,
+				Required:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+			"id": {
+				Type:     types.StringType,
+				Computed: true,
+				Optional: false,
+			},
+			"regex": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"reputation_command": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"reputation_script": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"format_script": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"layout": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"expiration": {
+				Type:       types.Int64Type,
+				Optional:   true,
+				Computed:   true,
+				Validators: []tfsdk.AttributeValidator{isValidExpiration{}},
+			},
+			"propagation_labels": {
+				Type:     types.SetType{ElemType: types.StringType},
+				Optional: true,
+				Computed: true,
+			},
+			"account": {
+				Type:          types.StringType,
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
+	}, nil
+}
+
+// NewResource instance
+func (r resourceIndicatorTypeType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceIndicatorType{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+type resourceIndicatorType struct {
+	p provider
+}
+
+// Create a new resource
+func (r resourceIndicatorType) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_indicator_type")
+	if !r.p.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource. This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
+		)
+		return
+	}
+
+	// Retrieve values from plan
+	var plan IndicatorType
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 5*time.Minute)
+	defer cancel()
+
+	// Create
+	indicatorTypeRequest := map[string]interface{}{}
+	resp.Diagnostics.Append(setIndicatorTypeRequest(ctx, plan, indicatorTypeRequest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var indicatorType map[string]interface{}
+	_, err := doRequest(ctx, r.p.client, http.MethodPost, r.p.accountPrefix(plan.Account)+"/reputation", indicatorTypeRequest, &indicatorType)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error creating indicator type", "Could not create indicator type", err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := indicatorTypeResult(indicatorType)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourceIndicatorType) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_indicator_type")
+	// Get current state
+	var state IndicatorType
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
+
+	// Get resource from API
+	indicatorType, err := getIndicatorType(ctx, r.p.client, r.p.accountPrefix(state.Account), state.Id.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting indicator type", "Could not get indicator type", err)
+		return
+	}
+	if indicatorType == nil {
+		logDebug(ctx, "Indicator type not found, removing from state")
+		// Remove resource from state
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := indicatorTypeResult(indicatorType)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = state.Account
+	result.Timeouts = state.Timeouts
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource
+func (r resourceIndicatorType) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_indicator_type")
+	// Get plan values
+	var plan IndicatorType
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 5*time.Minute)
+	defer cancel()
+
+	// Get current state
+	var state IndicatorType
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The whole indicator type is saved at once, so start from the current one to keep the attributes not in the plan
+	prefix := r.p.accountPrefix(plan.Account)
+	indicatorTypeRequest, err := getIndicatorType(ctx, r.p.client, prefix, state.Id.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting indicator type", "Could not get indicator type", err)
+		return
+	}
+	if indicatorTypeRequest == nil {
+		resp.Diagnostics.AddError(
+			"Error updating indicator type",
+			"Could not find indicator type "+state.Id.Value,
+		)
+		return
+	}
+	resp.Diagnostics.Append(setIndicatorTypeRequest(ctx, plan, indicatorTypeRequest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var indicatorType map[string]interface{}
+	_, err = doRequest(ctx, r.p.client, http.MethodPost, prefix+"/reputation", indicatorTypeRequest, &indicatorType)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error updating indicator type", "Could not update indicator type", err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := indicatorTypeResult(indicatorType)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourceIndicatorType) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_indicator_type")
+	// Get state
+	var state IndicatorType
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
+
+	// Delete
+	httpResponse, err := doRequest(ctx, r.p.client, http.MethodDelete, r.p.accountPrefix(state.Account)+"/reputation/"+url.PathEscape(state.Id.Value), nil, nil)
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting indicator type", "Could not delete indicator type", err)
+		return
+	}
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
+
+func (r resourceIndicatorType) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_indicator_type")
+	var diags diag.Diagnostics
+	accname := r.p.splitImportId(req.ID)
+	var acc, name, prefix string
+	if len(accname) == 1 {
+		name = req.ID
+	} else {
+		acc, name = accname[0], accname[1]
+		prefix = "/acc_" + acc
+	}
+	indicatorType, err := getIndicatorType(ctx, r.p.client, prefix, name)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error importing indicator type", "Could not import indicator type", err)
+		return
+	}
+	if indicatorType == nil {
+		resp.Diagnostics.AddError(
+			"Error importing indicator type",
+			"Could not find indicator type "+name,
+		)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := indicatorTypeResult(indicatorType)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if len(accname) == 1 {
+		result.Account = types.String{Null: true}
+	} else {
+		result.Account = types.String{Value: acc}
+	}
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// ModifyPlan plans the account, checking it is only given when the provider is managing a multi-tenant main host and
+// falling back to the provider's default account
+func (r resourceIndicatorType) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_indicator_type")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	r.p.planAccount(ctx, req, resp)
+}
+
+// getIndicatorType finds an indicator type by ID or name among those of the main tenant, or of the account prefix
+// addresses, returning nil if there is none. The API has no endpoint for a single indicator type.
+func getIndicatorType(ctx context.Context, client *openapi.APIClient, prefix string, identifier string) (map[string]interface{}, error) {
+	var indicatorTypes []map[string]interface{}
+	_, err := doRequest(ctx, client, http.MethodGet, prefix+"/reputations", nil, &indicatorTypes)
+	if err != nil {
+		return nil, err
+	}
+	for _, indicatorType := range indicatorTypes {
+		if indicatorType["id"] == identifier || indicatorType["details"] == identifier {
+			return indicatorType, nil
+		}
+	}
+	return nil, nil
+}
+
+// setIndicatorTypeRequest sets the attributes of the plan that are known on the indicator type sent to the API
+func setIndicatorTypeRequest(ctx context.Context, plan IndicatorType, indicatorType map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	indicatorType["details"] = plan.Name.Value
+	if !plan.Regex.Unknown {
+		indicatorType["regex"] = plan.Regex.Value
+	}
+	if !plan.ReputationCommand.Unknown {
+		indicatorType["reputationCommand"] = plan.ReputationCommand.Value
+	}
+	if !plan.ReputationScript.Unknown {
+		indicatorType["reputationScriptName"] = plan.ReputationScript.Value
+	}
+	if !plan.FormatScript.Unknown {
+		indicatorType["formatScript"] = plan.FormatScript.Value
+	}
+	if !plan.Layout.Unknown {
+		indicatorType["layout"] = plan.Layout.Value
+	}
+	if !plan.Expiration.Unknown && !plan.Expiration.Null {
+		indicatorType["expiration"] = plan.Expiration.Value
+	}
+	if !plan.PropagationLabels.Unknown && !plan.PropagationLabels.Null {
+		var propLabels []string
+		diags.Append(plan.PropagationLabels.ElementsAs(ctx, &propLabels, false)...)
+		indicatorType["propagationLabels"] = propLabels
+	}
+	return diags
+}
+
+// indicatorTypeResult maps an indicator type returned by the API to the resource schema, leaving the account and
+// timeouts to the caller
+func indicatorTypeResult(indicatorType map[string]interface{}) (IndicatorType, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result := IndicatorType{
+		Name:              types.String{Value: stringValue(indicatorType["details"])},
+		Id:                types.String{Value: stringValue(indicatorType["id"])},
+		Regex:             optionalString(indicatorType["regex"]),
+		ReputationCommand: optionalString(indicatorType["reputationCommand"]),
+		ReputationScript:  optionalString(indicatorType["reputationScriptName"]),
+		FormatScript:      optionalString(indicatorType["formatScript"]),
+		Layout:            optionalString(indicatorType["layout"]),
+		Expiration:        types.Int64{Value: int64Value(indicatorType["expiration"])},
+		PropagationLabels: stringSet(indicatorType["propagationLabels"]),
+	}
+	return result, diags
+}
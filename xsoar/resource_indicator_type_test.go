@@ -0,0 +1,151 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAccIndicatorType_basic(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccIndicatorTypeResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckIndicatorTypeResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccIndicatorTypeResourceBasic(rName),
+				Check:  testAccCheckIndicatorTypeResourceExists(rName),
+			},
+			{
+				ResourceName:      "xsoar_indicator_type." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccIndicatorTypeResourceUpdated(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIndicatorTypeResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_indicator_type."+rName, "reputation_command", "employee"),
+					resource.TestCheckResourceAttr("xsoar_indicator_type."+rName, "expiration", "10080"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccIndicatorType_expiration(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccIndicatorTypeResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckIndicatorTypeResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccIndicatorTypeResourceExpiration(rName, "10080"),
+				Check:  resource.TestCheckResourceAttr("xsoar_indicator_type."+rName, "expiration", "10080"),
+			},
+			{
+				// 0 switches the indicator type to never expiring its indicators
+				Config: testAccIndicatorTypeResourceExpiration(rName, "0"),
+				Check:  resource.TestCheckResourceAttr("xsoar_indicator_type."+rName, "expiration", "0"),
+			},
+			{
+				// leaving expiration out keeps whatever the server has
+				Config: testAccIndicatorTypeResourceBasic(rName),
+				Check:  resource.TestCheckResourceAttr("xsoar_indicator_type."+rName, "expiration", "0"),
+			},
+			{
+				Config:      testAccIndicatorTypeResourceExpiration(rName, "-1"),
+				ExpectError: regexp.MustCompile("Invalid Expiration Value"),
+			},
+		},
+	})
+}
+
+func testAccIndicatorTypeResourcePreCheck(t *testing.T) {}
+
+func testAccCheckIndicatorTypeResourceExists(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources["xsoar_indicator_type."+r]
+		if !ok {
+			return fmt.Errorf("not found: %s in %s", r, state.RootModule().Resources)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		indicatorType, err := getIndicatorType(context.Background(), openapiClient, "", rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting indicator type: " + err.Error())
+		}
+		if indicatorType == nil {
+			return fmt.Errorf("indicator type " + rs.Primary.ID + " not found")
+		}
+		return nil
+	}
+}
+
+func testAccCheckIndicatorTypeResourceDestroy(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		indicatorType, err := getIndicatorType(context.Background(), openapiClient, "", r)
+		if err != nil {
+			return fmt.Errorf("Error getting indicator type: " + err.Error())
+		}
+		if indicatorType != nil {
+			return fmt.Errorf("found indicator type when none was expected")
+		}
+		return nil
+	}
+}
+
+func testAccIndicatorTypeResourceBasic(name string) string {
+	c := `
+resource "xsoar_indicator_type" "{name}" {
+  name  = "{name}"
+  regex = "EMP-[0-9]{6}"
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccIndicatorTypeResourceUpdated(name string) string {
+	c := `
+resource "xsoar_indicator_type" "{name}" {
+  name               = "{name}"
+  regex              = "EMP-[0-9]{6}"
+  reputation_command = "employee"
+  expiration         = 10080
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccIndicatorTypeResourceExpiration(name string, expiration string) string {
+	c := `
+resource "xsoar_indicator_type" "{name}" {
+  name       = "{name}"
+  regex      = "EMP-[0-9]{6}"
+  expiration = {expiration}
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	c = strings.Replace(c, "{expiration}", expiration, -1)
+	return c
+}
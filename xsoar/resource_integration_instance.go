@@ -3,15 +3,14 @@ package xsoar
 import (
 	"context"
 	"encoding/json"
-	"io"
-	"log"
 	"net/http"
 	"reflect"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
@@ -95,6 +94,7 @@ func (r resourceIntegrationInstanceType) GetSchema(_ context.Context) (tfsdk.Sch
 			"account": {
 				Type:          types.StringType,
 				Optional:      true,
+				Computed:      true,
 				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
 			},
 			"incoming_mapper_id": {
@@ -111,6 +111,9 @@ func (r resourceIntegrationInstanceType) GetSchema(_ context.Context) (tfsdk.Sch
 				Required: true,
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -127,6 +130,7 @@ type resourceIntegrationInstance struct {
 
 // Create a new resource
 func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_integration_instance")
 	if !r.p.configured {
 		resp.Diagnostics.AddError(
 			"Provider not configured",
@@ -145,15 +149,14 @@ func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.Creat
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 5*time.Minute)
+	defer cancel()
 
 	// Create
 	// list integrations
 	integrations, _, err := r.p.client.DefaultApi.ListIntegrations(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing integration",
-			"Could not list integrations: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing integration", "Could not list integrations", err)
 		return
 	}
 	var moduleConfiguration []interface{}
@@ -218,7 +221,9 @@ func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.Creat
 			plan.PropagationLabels.ElementsAs(ctx, &propLabels, false)
 			moduleInstance["propagationLabels"] = propLabels
 			//moduleInstance["resetContext"] = false
-			moduleInstance["version"] = -1
+			if r.p.data.Server.supports(capabilityInstanceVersionOverride) {
+				moduleInstance["version"] = -1
+			}
 			break
 		}
 	}
@@ -275,22 +280,13 @@ func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.Creat
 	}
 
 	var integration map[string]interface{}
-	var httpResponse *http.Response
-	if plan.Account.Null || len(plan.Account.Value) == 0 {
-		integration, httpResponse, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstance(ctx).CreateIntegrationRequest(moduleInstance).Execute()
+	if accName, ok := r.p.accountName(plan.Account); !ok {
+		integration, _, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstance(ctx).CreateIntegrationRequest(moduleInstance).Execute()
 	} else {
-		integration, httpResponse, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstanceAccount(ctx, "acc_"+plan.Account.Value).CreateIntegrationRequest(moduleInstance).Execute()
+		integration, _, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstanceAccount(ctx, accName).CreateIntegrationRequest(moduleInstance).Execute()
 	}
 	if err != nil {
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
-		resp.Diagnostics.AddError(
-			"Error creating integration instance",
-			"Could not create integration instance: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error creating integration instance", "Could not create integration instance", err)
 		return
 	}
 
@@ -351,6 +347,8 @@ func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.Creat
 		result.EngineId = types.String{Null: true}
 	}
 
+	result.Timeouts = plan.Timeouts
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -361,6 +359,7 @@ func (r resourceIntegrationInstance) Create(ctx context.Context, req tfsdk.Creat
 
 // Read resource information
 func (r resourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_integration_instance")
 	// Get current state
 	var state IntegrationInstance
 	diags := req.State.Get(ctx, &state)
@@ -368,50 +367,36 @@ func (r resourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadRes
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get resource from API
 	var integration map[string]interface{}
 	var httpResponse *http.Response
 	var err error
-	if state.Account.Null || len(state.Account.Value) == 0 {
+	if accName, ok := r.p.accountName(state.Account); !ok {
 		integration, httpResponse, err = r.p.client.DefaultApi.GetIntegrationInstance(ctx).SetIdentifier(state.Id.Value).Execute()
 	} else {
 		var account map[string]interface{}
-		account, httpResponse, err = r.p.client.DefaultApi.GetAccount(ctx, "acc_"+state.Account.Value).Execute()
-		if err != nil {
-			log.Println(err.Error())
-			if httpResponse != nil {
-				body, _ := io.ReadAll(httpResponse.Body)
-				payload, _ := io.ReadAll(httpResponse.Request.Body)
-				log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-			}
-			resp.Diagnostics.AddError(
-				"Error getting integration instance",
-				"Could not verify account existence: "+err.Error(),
-			)
+		account, httpResponse, err = r.p.client.DefaultApi.GetAccount(ctx, accName).Execute()
+		if err != nil && !isNotFound(httpResponse, err) {
+			addAPIAttributeError(&resp.Diagnostics, path.Root("account"), "Error getting integration instance", "Could not verify account existence", err)
 			return
 		}
 		if account == nil {
+			logDebug(ctx, "Account not found, removing from state")
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		integration, httpResponse, err = r.p.client.DefaultApi.GetIntegrationInstanceAccount(ctx, "acc_"+state.Account.Value).SetIdentifier(state.Id.Value).Execute()
+		integration, httpResponse, err = r.p.client.DefaultApi.GetIntegrationInstanceAccount(ctx, accName).SetIdentifier(state.Id.Value).Execute()
 	}
-	if err != nil {
-		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
-		resp.Diagnostics.AddError(
-			"Error getting integration instance",
-			"Could not get integration instance: "+err.Error(),
-		)
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error getting integration instance", "Could not get integration instance", err)
 		return
 	}
 
 	if integration == nil {
+		logDebug(ctx, "Integration instance not found, removing from state")
 		resp.State.RemoveResource(ctx)
 		return
 	}
@@ -489,6 +474,8 @@ func (r resourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadRes
 		result.EngineId = types.String{Null: true}
 	}
 
+	result.Timeouts = state.Timeouts
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -499,6 +486,7 @@ func (r resourceIntegrationInstance) Read(ctx context.Context, req tfsdk.ReadRes
 
 // Update resource
 func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_integration_instance")
 	// Get plan values
 	var plan IntegrationInstance
 	diags := req.Plan.Get(ctx, &plan)
@@ -506,6 +494,8 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 5*time.Minute)
+	defer cancel()
 
 	// Get current state
 	var state IntegrationInstance
@@ -519,10 +509,7 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 	// list integrations
 	integrations, _, err := r.p.client.DefaultApi.ListIntegrations(ctx).Execute()
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error listing integration",
-			"Could not list integrations: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error listing integration", "Could not list integrations", err)
 		return
 	}
 	var moduleConfiguration []interface{}
@@ -587,7 +574,9 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 			plan.PropagationLabels.ElementsAs(ctx, &propLabels, false)
 			moduleInstance["propagationLabels"] = propLabels
 			//moduleInstance["resetContext"] = false
-			moduleInstance["version"] = -1
+			if r.p.data.Server.supports(capabilityInstanceVersionOverride) {
+				moduleInstance["version"] = -1
+			}
 			break
 		}
 	}
@@ -648,22 +637,13 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 	}
 
 	var integration map[string]interface{}
-	var httpResponse *http.Response
-	if state.Account.Null || len(state.Account.Value) == 0 {
-		integration, httpResponse, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstance(ctx).CreateIntegrationRequest(moduleInstance).Execute()
+	if accName, ok := r.p.accountName(state.Account); !ok {
+		integration, _, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstance(ctx).CreateIntegrationRequest(moduleInstance).Execute()
 	} else {
-		integration, httpResponse, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstanceAccount(ctx, "acc_"+plan.Account.Value).CreateIntegrationRequest(moduleInstance).Execute()
+		integration, _, err = r.p.client.DefaultApi.CreateUpdateIntegrationInstanceAccount(ctx, accName).CreateIntegrationRequest(moduleInstance).Execute()
 	}
 	if err != nil {
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
-		resp.Diagnostics.AddError(
-			"Error updating integration instance",
-			"Could not update integration instance: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error updating integration instance", "Could not update integration instance", err)
 		return
 	}
 
@@ -724,6 +704,8 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 		result.EngineId = types.String{Null: true}
 	}
 
+	result.Timeouts = plan.Timeouts
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -734,6 +716,7 @@ func (r resourceIntegrationInstance) Update(ctx context.Context, req tfsdk.Updat
 
 // Delete resource
 func (r resourceIntegrationInstance) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_integration_instance")
 	// Get state
 	var state IntegrationInstance
 	diags := req.State.Get(ctx, &state)
@@ -741,19 +724,19 @@ func (r resourceIntegrationInstance) Delete(ctx context.Context, req tfsdk.Delet
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
 
 	// Delete
+	var httpResponse *http.Response
 	var err error
-	if state.Account.Null || len(state.Account.Value) == 0 {
-		_, err = r.p.client.DefaultApi.DeleteIntegrationInstance(ctx, state.Id.Value).Execute()
+	if accName, ok := r.p.accountName(state.Account); !ok {
+		httpResponse, err = r.p.client.DefaultApi.DeleteIntegrationInstance(ctx, state.Id.Value).Execute()
 	} else {
-		_, err = r.p.client.DefaultApi.DeleteIntegrationInstanceAccount(ctx, state.Id.Value, "acc_"+state.Account.Value).Execute()
+		httpResponse, err = r.p.client.DefaultApi.DeleteIntegrationInstanceAccount(ctx, state.Id.Value, accName).Execute()
 	}
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting integration instance",
-			"Could not delete integration instance: "+err.Error(),
-		)
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting integration instance", "Could not delete integration instance", err)
 		return
 	}
 
@@ -762,8 +745,9 @@ func (r resourceIntegrationInstance) Delete(ctx context.Context, req tfsdk.Delet
 }
 
 func (r resourceIntegrationInstance) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_integration_instance")
 	var diags diag.Diagnostics
-	accname := strings.Split(req.ID, ".")
+	accname := r.p.splitImportId(req.ID)
 	var acc, name string
 	var integration map[string]interface{}
 	var err error
@@ -775,10 +759,7 @@ func (r resourceIntegrationInstance) ImportState(ctx context.Context, req tfsdk.
 		integration, _, err = r.p.client.DefaultApi.GetIntegrationInstanceAccount(ctx, "acc_"+acc).SetIdentifier(name).Execute()
 	}
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error getting integration instance",
-			"Could not get integration instance: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting integration instance", "Could not get integration instance", err)
 		return
 	}
 	if integration == nil {
@@ -858,3 +839,13 @@ func (r resourceIntegrationInstance) ImportState(ctx context.Context, req tfsdk.
 		return
 	}
 }
+
+// ModifyPlan plans the account, checking it is only given when the provider is managing a multi-tenant main host and
+// falling back to the provider's default account
+func (r resourceIntegrationInstance) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_integration_instance")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	r.p.planAccount(ctx, req, resp)
+}
@@ -0,0 +1,477 @@
+package xsoar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// layoutContainers are the views of an incident or indicator a layout container holds the layout of
+var layoutContainers = []string{
+	"close",
+	"details",
+	"detailsV2",
+	"edit",
+	"indicatorsDetails",
+	"indicatorsQuickView",
+	"mobile",
+	"quickView",
+}
+
+type isValidLayoutGroup struct{}
+
+func (v isValidLayoutGroup) Description(ctx context.Context) string {
+	return fmt.Sprint("group must be incident or indicator exactly")
+}
+
+func (v isValidLayoutGroup) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprint("group must be `incident` or `indicator` exactly")
+}
+
+func (v isValidLayoutGroup) Validate(ctx context.Context, request tfsdk.ValidateAttributeRequest, response *tfsdk.ValidateAttributeResponse) {
+	var str types.String
+	diags := tfsdk.ValueAs(ctx, request.AttributeConfig, &str)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() || str.Null || str.Unknown {
+		return
+	}
+	if !(str.Value == "incident" || str.Value == "indicator") {
+		response.Diagnostics.AddAttributeError(
+			request.AttributePath,
+			"Invalid Group Value",
+			fmt.Sprintf("Group must be one of incident or indicator exactly, got: %s.", str.Value),
+		)
+
+		return
+	}
+}
+
+type isValidLayout struct{}
+
+func (v isValidLayout) Description(ctx context.Context) string {
+	return fmt.Sprintf("layout must be a JSON object with keys among %s", strings.Join(layoutContainers, ", "))
+}
+
+func (v isValidLayout) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("layout must be a JSON object with keys among `%s`", strings.Join(layoutContainers, "`, `"))
+}
+
+func (v isValidLayout) Validate(ctx context.Context, request tfsdk.ValidateAttributeRequest, response *tfsdk.ValidateAttributeResponse) {
+	var str types.String
+	diags := tfsdk.ValueAs(ctx, request.AttributeConfig, &str)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() || str.Null || str.Unknown {
+		return
+	}
+	var containers map[string]interface{}
+	err := json.Unmarshal([]byte(str.Value), &containers)
+	if err != nil {
+		response.Diagnostics.AddAttributeError(
+			request.AttributePath,
+			"Invalid Layout Value",
+			"Layout must be a JSON object: "+err.Error(),
+		)
+		return
+	}
+	for key := range containers {
+		if !isLayoutContainer(key) {
+			response.Diagnostics.AddAttributeError(
+				request.AttributePath,
+				"Invalid Layout Value",
+				fmt.Sprintf("Layout keys must be among %s, got: %s.", strings.Join(layoutContainers, ", "), key),
+			)
+		}
+	}
+}
+
+func isLayoutContainer(key string) bool {
+	for _, container := range layoutContainers {
+		if key == container {
+			return true
+		}
+	}
+	return false
+}
+
+type resourceLayoutType struct{}
+
+// GetSchema Resource schema
+func (r resourceLayoutType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:     types.StringType,
+				Required: true,
+			},
+			"id": {
+				Type:     types.StringType,
+				Computed: true,
+				Optional: false,
+			},
+			"group": {
+				Type:          types.StringType,
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+				Validators:    []tfsdk.AttributeValidator{isValidLayoutGroup{}},
+			},
+			"description": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"layout": {
+				Type:       types.StringType,
+				Required:   true,
+				Validators: []tfsdk.AttributeValidator{isValidLayout{}},
+			},
+			"propagation_labels": {
+				Type:     types.SetType{ElemType: types.StringType},
+				Optional: true,
+				Computed: true,
+			},
+			"account": {
+				Type:          types.StringType,
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
+	}, nil
+}
+
+// NewResource instance
+func (r resourceLayoutType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceLayout{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+type resourceLayout struct {
+	p provider
+}
+
+// Create a new resource
+func (r resourceLayout) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_layout")
+	if !r.p.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource. This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
+		)
+		return
+	}
+
+	// Retrieve values from plan
+	var plan Layout
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 5*time.Minute)
+	defer cancel()
+
+	// Create
+	layoutRequest := map[string]interface{}{}
+	resp.Diagnostics.Append(setLayoutRequest(ctx, plan, layoutRequest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var layout map[string]interface{}
+	_, err := doRequest(ctx, r.p.client, http.MethodPost, r.p.accountPrefix(plan.Account)+"/layout/save", layoutRequest, &layout)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error creating layout", "Could not create layout", err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := layoutResult(layout, plan.Layout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourceLayout) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_layout")
+	// Get current state
+	var state Layout
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
+
+	// Get resource from API
+	layout, err := getLayout(ctx, r.p.client, r.p.accountPrefix(state.Account), state.Id.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting layout", "Could not get layout", err)
+		return
+	}
+	if layout == nil {
+		logDebug(ctx, "Layout not found, removing from state")
+		// Remove resource from state
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := layoutResult(layout, state.Layout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = state.Account
+	result.Timeouts = state.Timeouts
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource
+func (r resourceLayout) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_layout")
+	// Get plan values
+	var plan Layout
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 5*time.Minute)
+	defer cancel()
+
+	// Get current state
+	var state Layout
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The whole layout container is saved at once, so start from the current one to keep the attributes not in the plan
+	prefix := r.p.accountPrefix(plan.Account)
+	layoutRequest, err := getLayout(ctx, r.p.client, prefix, state.Id.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting layout", "Could not get layout", err)
+		return
+	}
+	if layoutRequest == nil {
+		resp.Diagnostics.AddError(
+			"Error updating layout",
+			"Could not find layout "+state.Id.Value,
+		)
+		return
+	}
+	resp.Diagnostics.Append(setLayoutRequest(ctx, plan, layoutRequest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var layout map[string]interface{}
+	_, err = doRequest(ctx, r.p.client, http.MethodPost, prefix+"/layout/save", layoutRequest, &layout)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error updating layout", "Could not update layout", err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := layoutResult(layout, plan.Layout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourceLayout) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_layout")
+	// Get state
+	var state Layout
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
+
+	// Delete
+	httpResponse, err := doRequest(ctx, r.p.client, http.MethodDelete, r.p.accountPrefix(state.Account)+"/layout/"+url.PathEscape(state.Id.Value), nil, nil)
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting layout", "Could not delete layout", err)
+		return
+	}
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
+
+func (r resourceLayout) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_layout")
+	var diags diag.Diagnostics
+	accname := r.p.splitImportId(req.ID)
+	var acc, name, prefix string
+	if len(accname) == 1 {
+		name = req.ID
+	} else {
+		acc, name = accname[0], accname[1]
+		prefix = "/acc_" + acc
+	}
+	layout, err := getLayout(ctx, r.p.client, prefix, name)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error importing layout", "Could not import layout", err)
+		return
+	}
+	if layout == nil {
+		resp.Diagnostics.AddError(
+			"Error importing layout",
+			"Could not find layout "+name,
+		)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result, diags := layoutResult(layout, types.String{Null: true})
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if len(accname) == 1 {
+		result.Account = types.String{Null: true}
+	} else {
+		result.Account = types.String{Value: acc}
+	}
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// ModifyPlan plans the account, checking it is only given when the provider is managing a multi-tenant main host and
+// falling back to the provider's default account
+func (r resourceLayout) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_layout")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	r.p.planAccount(ctx, req, resp)
+}
+
+// getLayout finds a layout container by ID or name among those of the main tenant, or of the account prefix
+// addresses, returning nil if there is none. The API has no endpoint for a single layout container.
+func getLayout(ctx context.Context, client *openapi.APIClient, prefix string, identifier string) (map[string]interface{}, error) {
+	var layouts []map[string]interface{}
+	_, err := doRequest(ctx, client, http.MethodGet, prefix+"/layouts", nil, &layouts)
+	if err != nil {
+		return nil, err
+	}
+	for _, layout := range layouts {
+		if layout["id"] == identifier || layout["name"] == identifier {
+			return layout, nil
+		}
+	}
+	return nil, nil
+}
+
+// setLayoutRequest sets the attributes of the plan that are known on the layout container sent to the API
+func setLayoutRequest(ctx context.Context, plan Layout, layout map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	layout["name"] = plan.Name.Value
+	if !plan.Group.Unknown && !plan.Group.Null {
+		layout["group"] = plan.Group.Value
+	}
+	layout["description"] = plan.Description.Value
+	var containers map[string]interface{}
+	err := json.Unmarshal([]byte(plan.Layout.Value), &containers)
+	if err != nil {
+		diags.AddError(
+			"Error unmarshalling json",
+			"Could not unmarshal layout json: "+err.Error(),
+		)
+		return diags
+	}
+	// the containers not in the plan are removed, rather than kept from the current layout container
+	for _, container := range layoutContainers {
+		delete(layout, container)
+	}
+	for container, value := range containers {
+		layout[container] = value
+	}
+	if !plan.PropagationLabels.Unknown && !plan.PropagationLabels.Null {
+		var propLabels []string
+		diags.Append(plan.PropagationLabels.ElementsAs(ctx, &propLabels, false)...)
+		layout["propagationLabels"] = propLabels
+	}
+	return diags
+}
+
+// layoutResult maps a layout container returned by the API to the resource schema, leaving the account and timeouts to
+// the caller. The server adds attributes such as IDs to tabs and sections, so the containers are cut down to the shape
+// of prior, the layout last planned or stored.
+func layoutResult(layout map[string]interface{}, prior types.String) (Layout, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result := Layout{
+		Name:              types.String{Value: stringValue(layout["name"])},
+		Id:                types.String{Value: stringValue(layout["id"])},
+		Group:             types.String{Value: stringValue(layout["group"])},
+		Description:       optionalString(layout["description"]),
+		PropagationLabels: stringSet(layout["propagationLabels"]),
+	}
+
+	found := map[string]interface{}{}
+	for _, container := range layoutContainers {
+		if value, ok := layout[container]; ok && value != nil {
+			found[container] = value
+		}
+	}
+	containers, err := jsonLike(found, prior)
+	if err != nil {
+		diags.AddError(
+			"Error marshalling json",
+			"Could not marshal json: "+err.Error(),
+		)
+		return result, diags
+	}
+	result.Layout = containers
+	return result, diags
+}
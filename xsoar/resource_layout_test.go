@@ -0,0 +1,237 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAccLayout_basic(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccLayoutResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckLayoutResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccLayoutResourceBasic(rName),
+				Check:  testAccCheckLayoutResourceExists(rName),
+			},
+			{
+				ResourceName:      "xsoar_layout." + rName,
+				ImportStateId:     rName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// an imported layout has nothing to cut the server's additions down to
+				ImportStateVerifyIgnore: []string{"layout"},
+			},
+			{
+				Config: testAccLayoutResourceUpdated(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckLayoutResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_layout."+rName, "description", "Layout with a close form"),
+					resource.TestCheckResourceAttr("xsoar_layout."+rName, "group", "incident"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLayout_normalization(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccLayoutResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckLayoutResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				// the layout is kept as written, without the tab IDs and flags the server adds
+				Config: testAccLayoutResourceFormatted(rName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckLayoutResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_layout."+rName, "layout", testAccLayoutFormatted),
+				),
+			},
+			{
+				// a tab renamed in the UI is still a change to the layout
+				PreConfig: func() {
+					layout, err := getLayout(context.Background(), openapiClient, "", rName)
+					if err != nil || layout == nil {
+						t.Fatalf("could not get layout %s: %v", rName, err)
+					}
+					tab := layout["detailsV2"].(map[string]interface{})["tabs"].([]interface{})[0].(map[string]interface{})
+					tab["name"] = "Overview"
+					_, err = doRequest(context.Background(), openapiClient, http.MethodPost, "/layout/save", layout, nil)
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccLayoutResourceFormatted(rName),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccLayout_invalid(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccLayoutResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccLayoutResourceInvalid(rName, "incident", `{ details_v2 = {} }`),
+				ExpectError: regexp.MustCompile("Layout keys must be among"),
+			},
+			{
+				Config:      testAccLayoutResourceInvalid(rName, "alert", `{ detailsV2 = {} }`),
+				ExpectError: regexp.MustCompile("Group must be one of incident or indicator"),
+			},
+		},
+	})
+}
+
+func testAccLayoutResourcePreCheck(t *testing.T) {}
+
+func testAccCheckLayoutResourceExists(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources["xsoar_layout."+r]
+		if !ok {
+			return fmt.Errorf("not found: %s in %s", r, state.RootModule().Resources)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		layout, err := getLayout(context.Background(), openapiClient, "", rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting layout: " + err.Error())
+		}
+		if layout == nil {
+			return fmt.Errorf("layout " + rs.Primary.ID + " not found")
+		}
+		return nil
+	}
+}
+
+func testAccCheckLayoutResourceDestroy(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		layout, err := getLayout(context.Background(), openapiClient, "", r)
+		if err != nil {
+			return fmt.Errorf("Error getting layout: " + err.Error())
+		}
+		if layout != nil {
+			return fmt.Errorf("found layout when none was expected")
+		}
+		return nil
+	}
+}
+
+func testAccLayoutResourceBasic(name string) string {
+	c := `
+resource "xsoar_layout" "{name}" {
+  name = "{name}"
+  layout = jsonencode({
+    detailsV2 = {
+      tabs = [{
+        name = "Summary"
+        type = "custom"
+        sections = [{
+          name  = "Case Details"
+          type  = "fields"
+          items = [{ fieldId = "severity" }, { fieldId = "owner" }]
+        }]
+      }]
+    }
+  })
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccLayoutResourceUpdated(name string) string {
+	c := `
+resource "xsoar_layout" "{name}" {
+  name        = "{name}"
+  description = "Layout with a close form"
+  layout = jsonencode({
+    detailsV2 = {
+      tabs = [{
+        name = "Summary"
+        type = "custom"
+        sections = [{
+          name  = "Case Details"
+          type  = "fields"
+          items = [{ fieldId = "severity" }, { fieldId = "owner" }]
+        }]
+      }]
+    }
+    close = {
+      tabs = [{
+        name     = "Close"
+        type     = "custom"
+        sections = [{ name = "Resolution", type = "fields", items = [{ fieldId = "closeNotes" }] }]
+      }]
+    }
+  })
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+// testAccLayoutFormatted is a layout written by hand rather than with jsonencode
+const testAccLayoutFormatted = `{
+  "detailsV2": {
+    "tabs": [
+      { "name": "Summary", "type": "custom", "sections": [] }
+    ]
+  }
+}
+`
+
+func testAccLayoutResourceFormatted(name string) string {
+	c := `
+resource "xsoar_layout" "{name}" {
+  name   = "{name}"
+  layout = <<EOT
+{layout}EOT
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	c = strings.Replace(c, "{layout}", testAccLayoutFormatted, -1)
+	return c
+}
+
+func testAccLayoutResourceInvalid(name string, group string, layout string) string {
+	c := `
+resource "xsoar_layout" "{name}" {
+  name   = "{name}"
+  group  = "{group}"
+  layout = jsonencode({layout})
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	c = strings.Replace(c, "{group}", group, -1)
+	c = strings.Replace(c, "{layout}", layout, -1)
+	return c
+}
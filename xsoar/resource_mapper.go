@@ -9,10 +9,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"io"
-	"log"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type isValidDirection struct{}
@@ -72,6 +71,7 @@ func (r resourceMapperType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Dia
 			"account": {
 				Type:          types.StringType,
 				Optional:      true,
+				Computed:      true,
 				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
 			},
 			"direction": {
@@ -80,6 +80,9 @@ func (r resourceMapperType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Dia
 				Validators: []tfsdk.AttributeValidator{isValidDirection{}},
 			},
 		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}, nil
 }
 
@@ -96,6 +99,7 @@ type resourceMapper struct {
 
 // Create a new resource
 func (r resourceMapper) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_mapper")
 	if !r.p.configured {
 		resp.Diagnostics.AddError(
 			"Provider not configured",
@@ -111,6 +115,8 @@ func (r resourceMapper) Create(ctx context.Context, req tfsdk.CreateResourceRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 5*time.Minute)
+	defer cancel()
 
 	// Create
 	mapperRequest := *openapi.NewCreateUpdateClassifierRequest()
@@ -135,23 +141,13 @@ func (r resourceMapper) Create(ctx context.Context, req tfsdk.CreateResourceRequ
 		mapperRequest.SetPropagationLabels(props)
 	}
 	var mapper openapi.InstanceClassifier
-	var httpResponse *http.Response
-	if plan.Account.Null || len(plan.Account.Value) == 0 {
-		mapper, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(mapperRequest).Execute()
+	if accName, ok := r.p.accountName(plan.Account); !ok {
+		mapper, _, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(mapperRequest).Execute()
 	} else {
-		mapper, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(mapperRequest).Execute()
+		mapper, _, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, accName).CreateUpdateClassifierAccountRequest(mapperRequest).Execute()
 	}
 	if err != nil {
-		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
-		resp.Diagnostics.AddError(
-			"Error creating mapper",
-			"Could not create mapper: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error creating mapper", "Could not create mapper", err)
 		return
 	}
 
@@ -182,6 +178,8 @@ func (r resourceMapper) Create(ctx context.Context, req tfsdk.CreateResourceRequ
 		result.Mapping = types.String{Value: m}
 	}
 
+	result.Timeouts = plan.Timeouts
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -192,6 +190,7 @@ func (r resourceMapper) Create(ctx context.Context, req tfsdk.CreateResourceRequ
 
 // Read resource information
 func (r resourceMapper) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_mapper")
 	// Get current state
 	var state Mapper
 	diags := req.State.Get(ctx, &state)
@@ -199,34 +198,26 @@ func (r resourceMapper) Read(ctx context.Context, req tfsdk.ReadResourceRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
 
 	// Get resource from API
 	var mapper openapi.InstanceClassifier
 	var httpResponse *http.Response
 	var err error
-	if state.Account.Null || len(state.Account.Value) == 0 {
+	if accName, ok := r.p.accountName(state.Account); !ok {
 		mapper, httpResponse, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(state.Name.Value).Execute()
 	} else {
-		mapper, httpResponse, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+state.Account.Value).SetIdentifier(state.Name.Value).Execute()
+		mapper, httpResponse, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, accName).SetIdentifier(state.Name.Value).Execute()
 	}
 	if err != nil {
-		// determine if the error is a not found error or not
-		if _, ok := err.(openapi.GenericOpenAPIError); ok {
-			log.Println("Mapper not found")
+		if isNotFound(httpResponse, err) {
+			logDebug(ctx, "Mapper not found, removing from state")
 			// Remove resource from state
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
-		resp.Diagnostics.AddError(
-			"Error creating classifier",
-			"Could not create classifier: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error getting mapper", "Could not get mapper", err)
 		return
 	}
 	var propLabels []attr.Value
@@ -256,6 +247,8 @@ func (r resourceMapper) Read(ctx context.Context, req tfsdk.ReadResourceRequest,
 		result.Mapping = types.String{Value: m}
 	}
 
+	result.Timeouts = state.Timeouts
+
 	// Generate resource state struct
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -266,6 +259,7 @@ func (r resourceMapper) Read(ctx context.Context, req tfsdk.ReadResourceRequest,
 
 // Update resource
 func (r resourceMapper) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_mapper")
 	// Get plan values
 	var plan Mapper
 	diags := req.Plan.Get(ctx, &plan)
@@ -273,6 +267,8 @@ func (r resourceMapper) Update(ctx context.Context, req tfsdk.UpdateResourceRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 5*time.Minute)
+	defer cancel()
 
 	// Get current state
 	var state Mapper
@@ -306,23 +302,13 @@ func (r resourceMapper) Update(ctx context.Context, req tfsdk.UpdateResourceRequ
 		mapperRequest.SetPropagationLabels(props)
 	}
 	var mapper openapi.InstanceClassifier
-	var httpResponse *http.Response
-	if plan.Account.Null || len(plan.Account.Value) == 0 {
-		mapper, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(mapperRequest).Execute()
+	if accName, ok := r.p.accountName(plan.Account); !ok {
+		mapper, _, err = r.p.client.DefaultApi.CreateUpdateClassifier(ctx).CreateUpdateClassifierRequest(mapperRequest).Execute()
 	} else {
-		mapper, httpResponse, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, "acc_"+plan.Account.Value).CreateUpdateClassifierAccountRequest(mapperRequest).Execute()
-	}
-	if httpResponse != nil {
-		body, _ := io.ReadAll(httpResponse.Body)
-		payload, _ := io.ReadAll(httpResponse.Request.Body)
-		log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
+		mapper, _, err = r.p.client.DefaultApi.CreateUpdateClassifierAccount(ctx, accName).CreateUpdateClassifierAccountRequest(mapperRequest).Execute()
 	}
 	if err != nil {
-		log.Println(err.Error())
-		resp.Diagnostics.AddError(
-			"Error updating mapper",
-			"Could not update mapper: "+err.Error(),
-		)
+		addAPIError(&resp.Diagnostics, "Error updating mapper", "Could not update mapper", err)
 		return
 	}
 
@@ -353,6 +339,8 @@ func (r resourceMapper) Update(ctx context.Context, req tfsdk.UpdateResourceRequ
 		result.Mapping = types.String{Value: m}
 	}
 
+	result.Timeouts = plan.Timeouts
+
 	// Set state
 	diags = resp.State.Set(ctx, result)
 	resp.Diagnostics.Append(diags...)
@@ -363,6 +351,7 @@ func (r resourceMapper) Update(ctx context.Context, req tfsdk.UpdateResourceRequ
 
 // Delete resource
 func (r resourceMapper) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_mapper")
 	// Get state
 	var state Mapper
 	diags := req.State.Get(ctx, &state)
@@ -370,26 +359,19 @@ func (r resourceMapper) Delete(ctx context.Context, req tfsdk.DeleteResourceRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
 
 	// Delete
-	var err error
 	var httpResponse *http.Response
-	if state.Account.Null || len(state.Account.Value) == 0 {
+	var err error
+	if accName, ok := r.p.accountName(state.Account); !ok {
 		httpResponse, err = r.p.client.DefaultApi.DeleteClassifier(ctx, state.Id.Value).Execute()
 	} else {
-		httpResponse, err = r.p.client.DefaultApi.DeleteClassifierAccount(ctx, state.Id.Value, "acc_"+state.Account.Value).Execute()
+		httpResponse, err = r.p.client.DefaultApi.DeleteClassifierAccount(ctx, state.Id.Value, accName).Execute()
 	}
-	if err != nil {
-		log.Println(err.Error())
-		if httpResponse != nil {
-			body, _ := io.ReadAll(httpResponse.Body)
-			payload, _ := io.ReadAll(httpResponse.Request.Body)
-			log.Printf("code: %d status: %s headers: %s body: %s payload: %s\n", httpResponse.StatusCode, httpResponse.Status, httpResponse.Header, string(body), string(payload))
-		}
-		resp.Diagnostics.AddError(
-			"Error deleting mapper",
-			"Could not delete mapper: "+err.Error(),
-		)
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting mapper", "Could not delete mapper", err)
 		return
 	}
 
@@ -398,8 +380,9 @@ func (r resourceMapper) Delete(ctx context.Context, req tfsdk.DeleteResourceRequ
 }
 
 func (r resourceMapper) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_mapper")
 	var diags diag.Diagnostics
-	accname := strings.Split(req.ID, ".")
+	accname := r.p.splitImportId(req.ID)
 	var acc, name string
 	var mapper openapi.InstanceClassifier
 	var err error
@@ -407,20 +390,14 @@ func (r resourceMapper) ImportState(ctx context.Context, req tfsdk.ImportResourc
 		name = req.ID
 		mapper, _, err = r.p.client.DefaultApi.GetClassifier(ctx).SetIdentifier(name).Execute()
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error importing mapper",
-				"Could not import mapper: "+err.Error(),
-			)
+			addAPIError(&resp.Diagnostics, "Error importing mapper", "Could not import mapper", err)
 			return
 		}
 	} else {
 		acc, name = accname[0], accname[1]
 		mapper, _, err = r.p.client.DefaultApi.GetClassifierAccount(ctx, "acc_"+acc).SetIdentifier(name).Execute()
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error importing mapper",
-				"Could not import mapper: "+err.Error(),
-			)
+			addAPIError(&resp.Diagnostics, "Error importing mapper", "Could not import mapper", err)
 			return
 		}
 	}
@@ -465,3 +442,13 @@ func (r resourceMapper) ImportState(ctx context.Context, req tfsdk.ImportResourc
 		return
 	}
 }
+
+// ModifyPlan plans the account, checking it is only given when the provider is managing a multi-tenant main host and
+// falling back to the provider's default account
+func (r resourceMapper) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_mapper")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	r.p.planAccount(ctx, req, resp)
+}
@@ -0,0 +1,403 @@
+package xsoar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/badarsebard/xsoar-sdk-go/openapi"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+type resourcePlaybookType struct{}
+
+// GetSchema Resource schema
+func (r resourcePlaybookType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	var planModifiers []tfsdk.AttributePlanModifier
+	return tfsdk.Schema{
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:     types.StringType,
+				Computed: true,
+			},
+			"id": {
+				Type:     types.StringType,
+				Computed: true,
+				Optional: false,
+			},
+			"version": {
+				Type:     types.Int64Type,
+				Computed: true,
+			},
+			"content": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"file": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"file_hash": {
+				Type:     types.StringType,
+				Optional: true,
+			},
+			"account": {
+				Type:          types.StringType,
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: append(planModifiers, tfsdk.RequiresReplace()),
+			},
+		},
+		Blocks: map[string]tfsdk.Block{
+			"timeouts": timeoutsBlock(),
+		},
+	}, nil
+}
+
+// NewResource instance
+func (r resourcePlaybookType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourcePlaybook{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+type resourcePlaybook struct {
+	p provider
+}
+
+// Create a new resource
+func (r resourcePlaybook) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_playbook")
+	if !r.p.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource. This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
+		)
+		return
+	}
+
+	// Retrieve values from plan
+	var plan Playbook
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationCreate, 5*time.Minute)
+	defer cancel()
+
+	// Create
+	content, err := playbookContent(plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating playbook",
+			"Could not read playbook: "+err.Error(),
+		)
+		return
+	}
+	var playbook map[string]interface{}
+	_, err = doUpload(ctx, r.p.client, r.p.accountPrefix(plan.Account)+"/playbook/save/yaml", "playbook.yml", content, &playbook)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error creating playbook", "Could not upload playbook", err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result := playbookResult(playbook)
+	result.Content = plan.Content
+	result.File = plan.File
+	result.FileHash = plan.FileHash
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourcePlaybook) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_playbook")
+	// Get current state
+	var state Playbook
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationRead, 5*time.Minute)
+	defer cancel()
+
+	// Get resource from API
+	prefix := r.p.accountPrefix(state.Account)
+	playbook, err := getPlaybook(ctx, r.p.client, prefix, state.Id.Value)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error getting playbook", "Could not get playbook", err)
+		return
+	}
+	if playbook == nil {
+		logDebug(ctx, "Playbook not found, removing from state")
+		// Remove resource from state
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result := playbookResult(playbook)
+	result.Content = state.Content
+	result.File = state.File
+	result.FileHash = state.FileHash
+	result.Account = state.Account
+	result.Timeouts = state.Timeouts
+
+	// The server bumps the version on every save, so a newer version than the one uploaded means the playbook was
+	// edited outside of Terraform. Storing what the server now has makes the next plan upload the configured one again.
+	if !state.Version.Null && !state.Version.Unknown && result.Version.Value != state.Version.Value {
+		logDebug(ctx, "Playbook was changed outside of Terraform")
+		exported, err := exportPlaybook(ctx, r.p.client, prefix, state.Id.Value)
+		if err != nil {
+			addAPIError(&resp.Diagnostics, "Error getting playbook", "Could not export playbook", err)
+			return
+		}
+		if !state.Content.Null {
+			result.Content = types.String{Value: string(exported)}
+		} else {
+			sum := sha256.Sum256(exported)
+			result.FileHash = types.String{Value: hex.EncodeToString(sum[:])}
+		}
+	}
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource
+func (r resourcePlaybook) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_playbook")
+	// Get plan values
+	var plan Playbook
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, plan.Timeouts, operationUpdate, 5*time.Minute)
+	defer cancel()
+
+	// Get current state
+	var state Playbook
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Update
+	content, err := playbookContent(plan)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating playbook",
+			"Could not read playbook: "+err.Error(),
+		)
+		return
+	}
+	prefix := r.p.accountPrefix(plan.Account)
+	var playbook map[string]interface{}
+	_, err = doUpload(ctx, r.p.client, prefix+"/playbook/save/yaml", "playbook.yml", content, &playbook)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error updating playbook", "Could not upload playbook", err)
+		return
+	}
+
+	// Map response body to resource schema attribute
+	result := playbookResult(playbook)
+	result.Content = plan.Content
+	result.File = plan.File
+	result.FileHash = plan.FileHash
+	result.Account = plan.Account
+	result.Timeouts = plan.Timeouts
+
+	// Uploading a playbook whose YAML has a new id creates another playbook rather than replacing this one
+	if result.Id.Value != state.Id.Value {
+		logDebug(ctx, "Playbook id changed, deleting the previous playbook")
+		httpResponse, err := doRequest(ctx, r.p.client, http.MethodPost, prefix+"/playbook/delete", map[string]interface{}{"id": state.Id.Value}, nil)
+		if err != nil && !isNotFound(httpResponse, err) {
+			addAPIError(&resp.Diagnostics, "Error updating playbook", "Could not delete previous playbook "+state.Id.Value, err)
+		}
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourcePlaybook) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	ctx = newLogContext(ctx, "xsoar_playbook")
+	// Get state
+	var state Playbook
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withTimeout(ctx, state.Timeouts, operationDelete, 5*time.Minute)
+	defer cancel()
+
+	// Delete
+	httpResponse, err := doRequest(ctx, r.p.client, http.MethodPost, r.p.accountPrefix(state.Account)+"/playbook/delete", map[string]interface{}{"id": state.Id.Value}, nil)
+	if err != nil && !isNotFound(httpResponse, err) {
+		addAPIError(&resp.Diagnostics, "Error deleting playbook", "Could not delete playbook", err)
+		return
+	}
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
+
+func (r resourcePlaybook) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	ctx = newLogContext(ctx, "xsoar_playbook")
+	var diags diag.Diagnostics
+	accid := r.p.splitImportId(req.ID)
+	var acc, id, prefix string
+	if len(accid) == 1 {
+		id = req.ID
+	} else {
+		acc, id = accid[0], accid[1]
+		prefix = "/acc_" + acc
+	}
+	playbook, err := getPlaybook(ctx, r.p.client, prefix, id)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Error importing playbook", "Could not import playbook", err)
+		return
+	}
+	if playbook == nil {
+		resp.Diagnostics.AddError(
+			"Error importing playbook",
+			"Could not find playbook "+id,
+		)
+		return
+	}
+
+	// Map response body to resource schema attribute. The YAML the playbook was uploaded from is not known, so the
+	// content, file and file_hash are left unset until the next apply uploads the configured one.
+	result := playbookResult(playbook)
+	result.Content = types.String{Null: true}
+	result.File = types.String{Null: true}
+	result.FileHash = types.String{Null: true}
+	if len(accid) == 1 {
+		result.Account = types.String{Null: true}
+	} else {
+		result.Account = types.String{Value: acc}
+	}
+
+	// Generate resource state struct
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// ModifyPlan plans the account, checking it is only given when the provider is managing a multi-tenant main host and
+// falling back to the provider's default account
+func (r resourcePlaybook) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	ctx = newLogContext(ctx, "xsoar_playbook")
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	r.p.planAccount(ctx, req, resp)
+}
+
+// ValidateConfig checks that exactly one of content and file is set, so a config with neither or both fails at plan
+// rather than part way through an apply
+func (r resourcePlaybook) ValidateConfig(ctx context.Context, req tfsdk.ValidateResourceConfigRequest, resp *tfsdk.ValidateResourceConfigResponse) {
+	ctx = newLogContext(ctx, "xsoar_playbook")
+	var content, file types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("content"), &content)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("file"), &file)...)
+	if resp.Diagnostics.HasError() || content.Unknown || file.Unknown {
+		return
+	}
+	hasContent := !content.Null && content.Value != ""
+	hasFile := !file.Null && file.Value != ""
+	if hasContent && hasFile {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("file"),
+			"Conflicting playbook sources",
+			"Only one of content and file may be set.",
+		)
+	} else if !hasContent && !hasFile {
+		resp.Diagnostics.AddError(
+			"Missing playbook source",
+			"One of content and file must be set.",
+		)
+	}
+}
+
+// playbookContent returns the YAML of the playbook in the plan, from either content or file
+func playbookContent(plan Playbook) ([]byte, error) {
+	hasContent := !plan.Content.Null && plan.Content.Value != ""
+	hasFile := !plan.File.Null && plan.File.Value != ""
+	if hasContent && hasFile {
+		return nil, errors.New("only one of content and file may be set")
+	}
+	if hasContent {
+		return []byte(plan.Content.Value), nil
+	}
+	if hasFile {
+		return os.ReadFile(plan.File.Value)
+	}
+	return nil, errors.New("one of content and file must be set")
+}
+
+// getPlaybook gets a playbook by ID from the main tenant, or from the account prefix addresses, returning nil if there
+// is none
+func getPlaybook(ctx context.Context, client *openapi.APIClient, prefix string, id string) (map[string]interface{}, error) {
+	var playbook map[string]interface{}
+	httpResponse, err := doRequest(ctx, client, http.MethodGet, prefix+"/playbook/"+url.PathEscape(id), nil, &playbook)
+	if isNotFound(httpResponse, err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return playbook, nil
+}
+
+// exportPlaybook gets the YAML of a playbook as the server now has it
+func exportPlaybook(ctx context.Context, client *openapi.APIClient, prefix string, id string) ([]byte, error) {
+	httpResponse, err := doRequest(ctx, client, http.MethodGet, prefix+"/playbook/"+url.PathEscape(id)+"/yaml", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(httpResponse.Body)
+}
+
+// playbookResult maps a playbook returned by the API to the resource schema, leaving the YAML source, account and
+// timeouts to the caller
+func playbookResult(playbook map[string]interface{}) Playbook {
+	return Playbook{
+		Name:    types.String{Value: stringValue(playbook["name"])},
+		Id:      types.String{Value: stringValue(playbook["id"])},
+		Version: types.Int64{Value: int64Value(playbook["version"])},
+	}
+}
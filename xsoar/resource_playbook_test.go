@@ -0,0 +1,245 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAccPlaybook_basic(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccPlaybookResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckPlaybookResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPlaybookResourceBasic(rName, "Created by Terraform"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPlaybookResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_playbook."+rName, "id", rName),
+					resource.TestCheckResourceAttr("xsoar_playbook."+rName, "name", rName+" Playbook"),
+				),
+			},
+			{
+				ResourceName:            "xsoar_playbook." + rName,
+				ImportStateId:           rName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"content"},
+			},
+			{
+				Config: testAccPlaybookResourceBasic(rName, "Updated by Terraform"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPlaybookResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_playbook."+rName, "version", "2"),
+				),
+			},
+			{
+				// an edit in the UI saves the playbook again, which should show up as a change to the content
+				PreConfig: func() {
+					content := testAccPlaybookYAML(rName, "Edited in the UI")
+					_, err := doUpload(context.Background(), openapiClient, "/playbook/save/yaml", "playbook.yml", []byte(content), nil)
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccPlaybookResourceBasic(rName, "Updated by Terraform"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccPlaybook_file(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	file := filepath.ToSlash(filepath.Join(t.TempDir(), "playbook.yml"))
+	writePlaybook := func(description string) {
+		if err := os.WriteFile(file, []byte(testAccPlaybookYAML(rName, description)), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePlaybook("Created by Terraform")
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccPlaybookResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		CheckDestroy: testAccCheckPlaybookResourceDestroy(rName),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPlaybookResourceFile(rName, file),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPlaybookResourceExists(rName),
+					resource.TestCheckResourceAttr("xsoar_playbook."+rName, "version", "1"),
+				),
+			},
+			{
+				// an edited file is uploaded again because its hash changes
+				PreConfig: func() { writePlaybook("Updated by Terraform") },
+				Config:    testAccPlaybookResourceFile(rName, file),
+				Check:     resource.TestCheckResourceAttr("xsoar_playbook."+rName, "version", "2"),
+			},
+			{
+				// an edit in the UI replaces the hash in state with that of the server's YAML
+				PreConfig: func() {
+					content := testAccPlaybookYAML(rName, "Edited in the UI")
+					_, err := doUpload(context.Background(), openapiClient, "/playbook/save/yaml", "playbook.yml", []byte(content), nil)
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccPlaybookResourceFile(rName, file),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// applying uploads the file again, after which there is nothing left to change
+				Config: testAccPlaybookResourceFile(rName, file),
+				Check:  resource.TestCheckResourceAttr("xsoar_playbook."+rName, "version", "4"),
+			},
+			{
+				// a playbook deleted outside of Terraform should be planned to be uploaded again
+				PreConfig: func() {
+					_, err := doRequest(context.Background(), openapiClient, http.MethodPost, "/playbook/delete", map[string]interface{}{"id": rName}, nil)
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccPlaybookResourceFile(rName, file),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccPlaybook_source(t *testing.T) {
+	rName := acctest.RandStringFromCharSet(5, acctest.CharSetAlpha)
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() { testAccPlaybookResourcePreCheck(t) },
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"xsoar": func() (tfprotov6.ProviderServer, error) {
+				return providerserver.NewProtocol6WithError(New()())()
+			},
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "xsoar_playbook" "` + rName + `" {
+  content = "id: ` + rName + `"
+  file    = "playbook.yml"
+}`,
+				ExpectError: regexp.MustCompile("Conflicting playbook sources"),
+			},
+			{
+				Config: `
+resource "xsoar_playbook" "` + rName + `" {
+  file_hash = "0"
+}`,
+				ExpectError: regexp.MustCompile("Missing playbook source"),
+			},
+		},
+	})
+}
+
+func testAccPlaybookResourcePreCheck(t *testing.T) {}
+
+func testAccCheckPlaybookResourceExists(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		rs, ok := state.RootModule().Resources["xsoar_playbook."+r]
+		if !ok {
+			return fmt.Errorf("not found: %s in %s", r, state.RootModule().Resources)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("no ID is set")
+		}
+
+		playbook, err := getPlaybook(context.Background(), openapiClient, "", rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting playbook: " + err.Error())
+		}
+		if playbook == nil {
+			return fmt.Errorf("playbook " + rs.Primary.ID + " not found")
+		}
+		return nil
+	}
+}
+
+func testAccCheckPlaybookResourceDestroy(r string) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		playbook, err := getPlaybook(context.Background(), openapiClient, "", r)
+		if err != nil {
+			return fmt.Errorf("Error getting playbook: " + err.Error())
+		}
+		if playbook != nil {
+			return fmt.Errorf("found playbook when none was expected")
+		}
+		return nil
+	}
+}
+
+func testAccPlaybookYAML(name string, description string) string {
+	c := `id: {name}
+version: -1
+name: {name} Playbook
+description: {description}
+starttaskid: "0"
+tasks:
+  "0":
+    id: "0"
+    taskid: 9c8e1a52-2e9b-4d8e-8a53-1d9c0c0f3e01
+    type: start
+    task:
+      id: 9c8e1a52-2e9b-4d8e-8a53-1d9c0c0f3e01
+      version: -1
+      name: ""
+      iscommand: false
+      brand: ""
+fromversion: 6.0.0
+`
+	c = strings.Replace(c, "{name}", name, -1)
+	c = strings.Replace(c, "{description}", description, -1)
+	return c
+}
+
+func testAccPlaybookResourceBasic(name string, description string) string {
+	c := `
+resource "xsoar_playbook" "{name}" {
+  content = <<-EOT
+{yaml}
+  EOT
+}`
+	c = strings.Replace(c, "{yaml}", testAccPlaybookYAML(name, description), -1)
+	c = strings.Replace(c, "{name}", name, -1)
+	return c
+}
+
+func testAccPlaybookResourceFile(name string, file string) string {
+	c := `
+resource "xsoar_playbook" "{name}" {
+  file      = "{file}"
+  file_hash = filesha256("{file}")
+}`
+	c = strings.Replace(c, "{name}", name, -1)
+	c = strings.Replace(c, "{file}", file, -1)
+	return c
+}
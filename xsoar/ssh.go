@@ -0,0 +1,466 @@
+package xsoar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// hostKeyMismatchError is returned by the host key callback when the key presented by the server does not match
+// the configured host_key or known_hosts entries. It is never retried.
+type hostKeyMismatchError struct {
+	address string
+	got     ssh.PublicKey
+	want    []string
+}
+
+func (e *hostKeyMismatchError) Error() string {
+	msg := fmt.Sprintf("host key verification failed for %s: server presented %s %s",
+		e.address, e.got.Type(), ssh.FingerprintSHA256(e.got))
+	if len(e.want) > 0 {
+		msg += fmt.Sprintf(", expected one of %v", e.want)
+	} else {
+		msg += ", which is not present in known_hosts"
+	}
+	return msg
+}
+
+// hostKeyCallback builds the host key verification from a pinned host_key, inline known_hosts content, or a
+// known_hosts_file path. ok is false if none of them are set, in which case the host key is not verified at all.
+func hostKeyCallback(hostKey, knownHosts, knownHostsFile types.String) (callback ssh.HostKeyCallback, ok bool, err error) {
+	var set int
+	for _, v := range []types.String{hostKey, knownHosts, knownHostsFile} {
+		if !v.Null && v.Value != "" {
+			set++
+		}
+	}
+	if set == 0 {
+		return ssh.InsecureIgnoreHostKey(), false, nil
+	}
+	if set > 1 {
+		return nil, true, errors.New("only one of host_key, known_hosts and known_hosts_file may be set")
+	}
+
+	if !hostKey.Null && hostKey.Value != "" {
+		pinned, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey.Value))
+		if err != nil {
+			return nil, true, fmt.Errorf("could not parse host_key: %s", err)
+		}
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if !bytes.Equal(key.Marshal(), pinned.Marshal()) {
+				return &hostKeyMismatchError{
+					address: hostname,
+					got:     key,
+					want:    []string{pinned.Type() + " " + ssh.FingerprintSHA256(pinned)},
+				}
+			}
+			return nil
+		}, true, nil
+	}
+
+	file := knownHostsFile.Value
+	if !knownHosts.Null && knownHosts.Value != "" {
+		// knownhosts only reads from files, so stage the inline content in a temporary one
+		f, err := os.CreateTemp("", "xsoar_known_hosts")
+		if err != nil {
+			return nil, true, fmt.Errorf("could not stage known_hosts: %s", err)
+		}
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(knownHosts.Value)
+		f.Close()
+		if err != nil {
+			return nil, true, fmt.Errorf("could not stage known_hosts: %s", err)
+		}
+		file = f.Name()
+	}
+	known, err := knownhosts.New(file)
+	if err != nil {
+		return nil, true, fmt.Errorf("could not load known_hosts: %s", err)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := known(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) {
+			var want []string
+			for _, k := range keyErr.Want {
+				want = append(want, k.Key.Type()+" "+ssh.FingerprintSHA256(k.Key))
+			}
+			return &hostKeyMismatchError{address: hostname, got: key, want: want}
+		}
+		return err
+	}, true, nil
+}
+
+// sshAuth holds the credentials for one SSH hop.
+type sshAuth struct {
+	key         types.String
+	passphrase  types.String
+	certificate types.String
+	password    types.String
+	agent       types.Bool
+}
+
+// authMethods builds the SSH authentication methods for a hop. Keys that cannot be parsed are reported immediately
+// rather than surfacing later as a failed handshake. The returned closer releases the connection to the SSH agent,
+// if one was opened, and must be called once the connection has been established.
+func authMethods(auth sshAuth, attr path.Path) ([]ssh.AuthMethod, func(), diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var methods []ssh.AuthMethod
+	closer := func() {}
+
+	if !auth.key.Null && len(auth.key.Value) > 0 {
+		var signer ssh.Signer
+		var err error
+		if !auth.passphrase.Null && len(auth.passphrase.Value) > 0 {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(auth.key.Value), []byte(auth.passphrase.Value))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(auth.key.Value))
+		}
+		if _, ok := err.(*ssh.PassphraseMissingError); ok {
+			diags.AddAttributeError(
+				attr.AtName("ssh_key"),
+				"Encrypted SSH key",
+				"The SSH private key is encrypted; set ssh_key_passphrase to decrypt it.",
+			)
+			return nil, closer, diags
+		}
+		if err != nil {
+			diags.AddAttributeError(
+				attr.AtName("ssh_key"),
+				"Invalid SSH key",
+				"Could not parse SSH private key: "+err.Error(),
+			)
+			return nil, closer, diags
+		}
+		if !auth.certificate.Null && len(auth.certificate.Value) > 0 {
+			pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(auth.certificate.Value))
+			cert, ok := pub.(*ssh.Certificate)
+			if err != nil || !ok {
+				diags.AddAttributeError(
+					attr.AtName("ssh_certificate"),
+					"Invalid SSH certificate",
+					"ssh_certificate must be an OpenSSH certificate for ssh_key.",
+				)
+				return nil, closer, diags
+			}
+			signer, err = ssh.NewCertSigner(cert, signer)
+			if err != nil {
+				diags.AddAttributeError(
+					attr.AtName("ssh_certificate"),
+					"Invalid SSH certificate",
+					"Could not use ssh_certificate with ssh_key: "+err.Error(),
+				)
+				return nil, closer, diags
+			}
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	} else if !auth.certificate.Null && len(auth.certificate.Value) > 0 {
+		diags.AddAttributeError(
+			attr.AtName("ssh_certificate"),
+			"Missing SSH key",
+			"ssh_certificate requires ssh_key to be set.",
+		)
+		return nil, closer, diags
+	}
+
+	// the agent is used when asked for, or by default when no other credentials are given
+	useAgent := auth.agent.Value || (auth.agent.Null && len(methods) == 0 && (auth.password.Null || len(auth.password.Value) == 0))
+	if useAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if len(socket) == 0 {
+			if auth.agent.Value {
+				diags.AddAttributeError(
+					attr.AtName("ssh_agent"),
+					"SSH agent not available",
+					"ssh_agent is set but SSH_AUTH_SOCK is not set in the environment.",
+				)
+				return nil, closer, diags
+			}
+		} else {
+			agentConn, err := net.Dial("unix", socket)
+			if err != nil {
+				diags.AddAttributeError(
+					attr.AtName("ssh_agent"),
+					"SSH agent not available",
+					"Could not connect to the SSH agent at "+socket+": "+err.Error(),
+				)
+				return nil, closer, diags
+			}
+			closer = func() { agentConn.Close() }
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+		}
+	}
+
+	if !auth.password.Null && len(auth.password.Value) > 0 {
+		methods = append(methods, ssh.Password(auth.password.Value))
+	}
+
+	if len(methods) == 0 {
+		diags.AddAttributeError(
+			attr.AtName("ssh_key"),
+			"No SSH credentials",
+			"One of ssh_key, ssh_password or an SSH agent through SSH_AUTH_SOCK is required to connect.",
+		)
+	}
+	return methods, closer, diags
+}
+
+// clientConfig builds the SSH client configuration for a user, authentication methods and host key callback. Host
+// key mismatches seen by the callback are recorded in mismatch, since ssh.Dial flattens callback errors into strings.
+func clientConfig(user string, auth []ssh.AuthMethod, callback ssh.HostKeyCallback, mismatch **hostKeyMismatchError) *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User: user,
+		Auth: auth,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			err := callback(hostname, remote, key)
+			if m, ok := err.(*hostKeyMismatchError); ok {
+				*mismatch = m
+			}
+			return err
+		},
+	}
+}
+
+// dialHost opens an SSH connection to the host's server_url, tunnelled through the bastion if one is configured,
+// retrying until timeout. Host key mismatches fail immediately rather than being retried. A warning is returned for
+// each hop whose host key is not being verified.
+func dialHost(ctx context.Context, host Host, timeout time.Duration) (*ssh.Client, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	callback, verified, err := hostKeyCallback(host.HostKey, host.KnownHosts, host.KnownHostsFile)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("host_key"),
+			"Invalid host key verification settings",
+			err.Error(),
+		)
+		return nil, diags
+	}
+	if !verified {
+		diags.AddWarning(
+			"Host key not verified",
+			"Neither host_key, known_hosts nor known_hosts_file is set for "+host.ServerUrl.Value+", so the identity of the SSH server the installer is run on is not verified.",
+		)
+	}
+	hostAuth, closeAgent, authDiags := authMethods(sshAuth{
+		key:         host.SSHKey,
+		passphrase:  host.SSHKeyPassphrase,
+		certificate: host.SSHCertificate,
+		password:    host.SSHPassword,
+		agent:       host.SSHAgent,
+	}, path.Empty())
+	diags.Append(authDiags...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	defer closeAgent()
+	var mismatch *hostKeyMismatchError
+	hostConfig := clientConfig(host.SSHUser.Value, hostAuth, callback, &mismatch)
+
+	// the bastion's host key is checked separately, so a mismatch is reported on the attribute that pins it
+	var bastionMismatch *hostKeyMismatchError
+	var bastionConfig *ssh.ClientConfig
+	var bastion Bastion
+	if len(host.Bastion) > 0 {
+		bastion = host.Bastion[0]
+		bastionCallback, verified, err := hostKeyCallback(bastion.HostKey, types.String{Null: true}, types.String{Null: true})
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("bastion").AtListIndex(0).AtName("host_key"),
+				"Invalid bastion host key",
+				err.Error(),
+			)
+			return nil, diags
+		}
+		if !verified {
+			diags.AddWarning(
+				"Bastion host key not verified",
+				"host_key is not set for the bastion "+bastion.Address.Value+", so the identity of the jump host is not verified.",
+			)
+		}
+		// the bastion's own user and key default to the host's credentials
+		user := bastion.SSHUser.Value
+		if bastion.SSHUser.Null || user == "" {
+			user = host.SSHUser.Value
+		}
+		bastionAuth := hostAuth
+		if !bastion.SSHKey.Null && len(bastion.SSHKey.Value) > 0 {
+			bastionAuth, _, authDiags = authMethods(sshAuth{
+				key:        bastion.SSHKey,
+				passphrase: bastion.SSHKeyPassphrase,
+				agent:      types.Bool{Value: false},
+			}, path.Root("bastion").AtListIndex(0))
+			diags.Append(authDiags...)
+			if diags.HasError() {
+				return nil, diags
+			}
+		}
+		bastionConfig = clientConfig(user, bastionAuth, bastionCallback, &bastionMismatch)
+	}
+
+	var conn *ssh.Client
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err = poll(dialCtx, time.Second, 15*time.Second, func() *resource.RetryError {
+		var conErr error
+		if bastionConfig == nil {
+			conn, conErr = ssh.Dial("tcp", host.ServerUrl.Value, hostConfig)
+		} else {
+			conn, conErr = dialThroughBastion(bastion.Address.Value, bastionConfig, host.ServerUrl.Value, hostConfig)
+		}
+		if bastionMismatch != nil {
+			return resource.NonRetryableError(bastionMismatch)
+		}
+		if mismatch != nil {
+			return resource.NonRetryableError(mismatch)
+		}
+		if conErr != nil {
+			return resource.RetryableError(fmt.Errorf("error connecting to host over ssh: " + conErr.Error()))
+		}
+		return nil
+	})
+	if bastionMismatch != nil {
+		diags.AddAttributeError(
+			path.Root("bastion").AtListIndex(0).AtName("host_key"),
+			"SSH bastion host key mismatch",
+			bastionMismatch.Error()+". Refusing to connect; update the bastion host_key if the bastion was legitimately rebuilt.",
+		)
+		return nil, diags
+	}
+	if mismatch != nil {
+		diags.AddAttributeError(
+			path.Root("server_url"),
+			"SSH host key mismatch",
+			mismatch.Error()+". Refusing to connect; update host_key or known_hosts if the host was legitimately rebuilt.",
+		)
+		return nil, diags
+	}
+	if err != nil {
+		diags.AddError(
+			"Error connecting to host",
+			"Could not connect to host over ssh: "+err.Error(),
+		)
+		return nil, diags
+	}
+	return conn, diags
+}
+
+// runCommand runs cmd in the ssh session, closing the session to stop it if the context is done first.
+func runCommand(ctx context.Context, session *ssh.Session, cmd string) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(cmd)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		session.Close()
+		return ctx.Err()
+	}
+}
+
+// dialThroughBastion connects to the bastion and opens the connection to the host over a tunnel through it. The
+// bastion connection is closed along with the returned client.
+func dialThroughBastion(bastionAddress string, bastionConfig *ssh.ClientConfig, address string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	jump, err := ssh.Dial("tcp", bastionAddress, bastionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to bastion %s: %s", bastionAddress, err)
+	}
+	tunnel, err := jump.Dial("tcp", address)
+	if err != nil {
+		jump.Close()
+		return nil, fmt.Errorf("could not open tunnel to %s through bastion: %s", address, err)
+	}
+	c, chans, reqs, err := ssh.NewClientConn(tunnel, address, config)
+	if err != nil {
+		tunnel.Close()
+		jump.Close()
+		return nil, err
+	}
+	client := ssh.NewClient(c, chans, reqs)
+	go func() {
+		client.Wait()
+		jump.Close()
+	}()
+	return client, nil
+}
+
+// uploadFile copies the contents of src to dest on the remote host using the scp protocol, so that nothing about the
+// file needs to appear on the remote command line besides its destination.
+func uploadFile(conn *ssh.Client, src *os.File, dest string, mode os.FileMode) error {
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	session, err := conn.NewSession()
+	if err != nil {
+		return fmt.Errorf("could not create ssh session: %s", err)
+	}
+	defer session.Close()
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	ack := bufio.NewReader(stdout)
+	if err = session.Start("scp -qt " + shellQuote(dest)); err != nil {
+		return fmt.Errorf("could not start scp: %s", err)
+	}
+	if err = scpAck(ack); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdin, "C%04o %d %s\n", mode.Perm(), info.Size(), filepath.Base(dest))
+	if err != nil {
+		return err
+	}
+	if err = scpAck(ack); err != nil {
+		return err
+	}
+	if _, err = io.Copy(stdin, src); err != nil {
+		return err
+	}
+	if _, err = stdin.Write([]byte{0}); err != nil {
+		return err
+	}
+	if err = scpAck(ack); err != nil {
+		return err
+	}
+	stdin.Close()
+	return session.Wait()
+}
+
+// scpAck reads a single scp acknowledgement, turning warnings and errors from the remote end into an error.
+func scpAck(r *bufio.Reader) error {
+	b, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("could not read scp response: %s", err)
+	}
+	if b == 0 {
+		return nil
+	}
+	msg, _ := r.ReadString('\n')
+	return fmt.Errorf("scp: %s", strings.TrimSpace(msg))
+}
+
+// shellQuote quotes s for use as a single argument in a POSIX shell command.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
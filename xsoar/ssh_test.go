@@ -0,0 +1,120 @@
+package xsoar
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", "''"},
+		{"plain", "/tmp/installer.sh", "'/tmp/installer.sh'"},
+		{"spaces", "a b", "'a b'"},
+		{"single quote", "it's", `'it'"'"'s'`},
+		{"only quotes", "''", `''"'"''"'"''`},
+		{"expansions", "$HOME `id` $(id)", "'$HOME `id` $(id)'"},
+		{"separators", "a; b && c | d", "'a; b && c | d'"},
+		{"newline", "a\nb", "'a\nb'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shellQuote(tt.in); got != tt.want {
+				t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func testHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	hostKey := testHostKey(t)
+	otherKey := testHostKey(t)
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostKey)))
+	knownHosts := knownhosts.Line([]string{"xsoar-host.example.com"}, hostKey)
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(knownHostsFile, []byte(knownHosts+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	set := func(v string) types.String { return types.String{Value: v} }
+	unset := types.String{Null: true}
+	const (
+		accepted = iota
+		mismatch
+		rejected
+	)
+	tests := []struct {
+		name           string
+		hostKey        types.String
+		knownHosts     types.String
+		knownHostsFile types.String
+		hostname       string
+		key            ssh.PublicKey
+		wantOk         bool
+		wantErr        bool
+		want           int
+	}{
+		{"nothing set accepts any key", unset, unset, unset, "xsoar-host.example.com:22", otherKey, false, false, accepted},
+		{"empty values count as unset", set(""), set(""), unset, "xsoar-host.example.com:22", otherKey, false, false, accepted},
+		{"more than one set", set(authorizedKey), set(knownHosts), unset, "", nil, true, true, accepted},
+		{"unparseable host key", set("not a key"), unset, unset, "", nil, true, true, accepted},
+		{"missing known hosts file", unset, unset, set(filepath.Join(t.TempDir(), "missing")), "", nil, true, true, accepted},
+		{"pinned key matches", set(authorizedKey), unset, unset, "xsoar-host.example.com:22", hostKey, true, false, accepted},
+		{"pinned key differs", set(authorizedKey), unset, unset, "xsoar-host.example.com:22", otherKey, true, false, mismatch},
+		{"known hosts match", unset, set(knownHosts), unset, "xsoar-host.example.com:22", hostKey, true, false, accepted},
+		{"known hosts differ", unset, set(knownHosts), unset, "xsoar-host.example.com:22", otherKey, true, false, mismatch},
+		{"known hosts lack host", unset, set(knownHosts), unset, "other-host.example.com:22", hostKey, true, false, mismatch},
+		{"known hosts on another port", unset, set(knownHosts), unset, "xsoar-host.example.com:2222", hostKey, true, false, mismatch},
+		{"known hosts file match", unset, unset, set(knownHostsFile), "xsoar-host.example.com:22", hostKey, true, false, accepted},
+		{"known hosts file differ", unset, unset, set(knownHostsFile), "xsoar-host.example.com:22", otherKey, true, false, mismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callback, ok, err := hostKeyCallback(tt.hostKey, tt.knownHosts, tt.knownHostsFile)
+			if ok != tt.wantOk {
+				t.Errorf("ok = %t, want %t", ok, tt.wantOk)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			remote := &net.TCPAddr{IP: net.ParseIP("192.0.2.10"), Port: 22}
+			err = callback(tt.hostname, remote, tt.key)
+			var mismatchErr *hostKeyMismatchError
+			switch {
+			case tt.want == accepted && err != nil:
+				t.Errorf("key rejected: %s", err)
+			case tt.want == mismatch && !errors.As(err, &mismatchErr):
+				t.Errorf("got %v, want a host key mismatch", err)
+			case tt.want == mismatch && !strings.Contains(err.Error(), ssh.FingerprintSHA256(tt.key)):
+				t.Errorf("mismatch %q does not name the presented key", err)
+			}
+		})
+	}
+}
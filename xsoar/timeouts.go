@@ -0,0 +1,104 @@
+package xsoar
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"time"
+)
+
+const (
+	operationCreate = "create"
+	operationRead   = "read"
+	operationUpdate = "update"
+	operationDelete = "delete"
+)
+
+// timeoutsBlock is the timeouts block shared by every resource, and by the data sources that use a resource's model.
+// Each operation's timeout is a duration string such as "30s", "10m" or "2h".
+func timeoutsBlock() tfsdk.Block {
+	attributes := map[string]tfsdk.Attribute{}
+	for _, operation := range []string{operationCreate, operationRead, operationUpdate, operationDelete} {
+		attributes[operation] = tfsdk.Attribute{
+			Type:       types.StringType,
+			Optional:   true,
+			Validators: []tfsdk.AttributeValidator{isValidDuration{}},
+		}
+	}
+	return tfsdk.Block{
+		NestingMode: tfsdk.BlockNestingModeList,
+		MaxItems:    1,
+		Attributes:  attributes,
+	}
+}
+
+// operationTimeout returns the configured timeout for an operation, or fallback if the timeouts block doesn't set one.
+func operationTimeout(timeouts []Timeouts, operation string, fallback time.Duration) time.Duration {
+	if len(timeouts) == 0 {
+		return fallback
+	}
+	var value types.String
+	switch operation {
+	case operationCreate:
+		value = timeouts[0].Create
+	case operationRead:
+		value = timeouts[0].Read
+	case operationUpdate:
+		value = timeouts[0].Update
+	case operationDelete:
+		value = timeouts[0].Delete
+	}
+	if value.Null || value.Unknown || len(value.Value) == 0 {
+		return fallback
+	}
+	timeout, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fallback
+	}
+	return timeout
+}
+
+// withTimeout returns a context that is cancelled once the operation's timeout has passed.
+func withTimeout(ctx context.Context, timeouts []Timeouts, operation string, fallback time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, operationTimeout(timeouts, operation, fallback))
+}
+
+// sleep waits for d, returning early with the context's error if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type isValidDuration struct{}
+
+func (v isValidDuration) Description(ctx context.Context) string {
+	return "value must be a duration such as 30s, 10m or 2h"
+}
+
+func (v isValidDuration) MarkdownDescription(ctx context.Context) string {
+	return "value must be a duration such as `30s`, `10m` or `2h`"
+}
+
+func (v isValidDuration) Validate(ctx context.Context, request tfsdk.ValidateAttributeRequest, response *tfsdk.ValidateAttributeResponse) {
+	var value types.String
+	diags := tfsdk.ValueAs(ctx, request.AttributeConfig, &value)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() || value.Null || value.Unknown {
+		return
+	}
+	timeout, err := time.ParseDuration(value.Value)
+	if err != nil || timeout <= 0 {
+		response.Diagnostics.AddAttributeError(
+			request.AttributePath,
+			"Invalid Timeout",
+			fmt.Sprintf("%q is not a positive duration such as 30s, 10m or 2h.", value.Value),
+		)
+	}
+}
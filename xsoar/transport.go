@@ -0,0 +1,311 @@
+package xsoar
+
+import (
+	"bytes"
+	"context"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"golang.org/x/net/http/httpproxy"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+var defaultRetryStatusCodes = []int64{429, 502, 503, 504}
+
+// unprocessedStatusCodes are the retryable status codes that mean the server turned the request away without acting on
+// it, so that even a request that creates something can be sent again
+var unprocessedStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusServiceUnavailable: true,
+}
+
+// retryTransport retries requests that fail with a connection error or one of the retryable status codes, backing off
+// exponentially between attempts. A Retry-After header sent by the server is honoured up to the maximum backoff.
+// Requests that are not idempotent, such as the POSTs that create objects, may already have been carried out when
+// the connection drops or a gateway times out, so they are only retried when the server cannot have acted on them.
+type retryTransport struct {
+	base        http.RoundTripper
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	statusCodes map[int]bool
+}
+
+func newRetryTransport(base http.RoundTripper, retry providerRetry) *retryTransport {
+	t := &retryTransport{
+		base:        base,
+		maxAttempts: 4,
+		minBackoff:  time.Second,
+		maxBackoff:  30 * time.Second,
+		statusCodes: map[int]bool{},
+	}
+	if !retry.MaxAttempts.Null {
+		t.maxAttempts = int(retry.MaxAttempts.Value)
+	}
+	if !retry.MinBackoff.Null {
+		t.minBackoff = time.Duration(retry.MinBackoff.Value) * time.Second
+	}
+	if !retry.MaxBackoff.Null {
+		t.maxBackoff = time.Duration(retry.MaxBackoff.Value) * time.Second
+	}
+	codes := defaultRetryStatusCodes
+	if retry.StatusCodes != nil {
+		codes = retry.StatusCodes
+	}
+	for _, code := range codes {
+		t.statusCodes[int(code)] = true
+	}
+	return t
+}
+
+// noRetryKey marks a request context whose requests are sent only once
+type noRetryKey struct{}
+
+// withoutRetries returns a context whose requests the retry transport sends only once
+func withoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Context().Value(noRetryKey{}) != nil {
+		return t.base.RoundTrip(req)
+	}
+
+	// the body has to be replayed on every attempt
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	idempotent := isIdempotent(req.Method)
+	var resp *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		// RoundTrippers must not modify the request, so every attempt sends a copy with its own body
+		attemptReq := req.Clone(req.Context())
+		if body != nil {
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		resp, err = t.base.RoundTrip(attemptReq)
+		if attempt >= t.maxAttempts || req.Context().Err() != nil {
+			return resp, err
+		}
+		if err == nil && !t.statusCodes[resp.StatusCode] {
+			return resp, nil
+		}
+		if !idempotent && !(err == nil && unprocessedStatusCodes[resp.StatusCode]) && !(err != nil && notSent(err)) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if err != nil {
+			logDebug(req.Context(), "Request failed, retrying", map[string]interface{}{
+				"method": req.Method,
+				"path":   req.URL.Path,
+				"error":  err.Error(),
+				"wait":   wait.String(),
+			})
+		} else {
+			logDebug(req.Context(), "Retryable response, retrying", map[string]interface{}{
+				"method": req.Method,
+				"path":   req.URL.Path,
+				"status": resp.StatusCode,
+				"wait":   wait.String(),
+			})
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// isIdempotent reports whether sending a request with method more than once has the same effect as sending it once.
+// PUT is not, as XSOAR creates objects with it, such as integration instances saved without an id.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// notSent reports whether a request failed before any of it reached the server, because the connection to the server
+// or proxy could not be made
+func notSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && (opErr.Op == "dial" || opErr.Op == "proxyconnect")
+}
+
+// backoff returns how long to wait before the next attempt, doubling from minBackoff with up to 50% jitter and
+// capped at maxBackoff.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait := time.Duration(seconds) * time.Second
+			if wait > t.maxBackoff {
+				wait = t.maxBackoff
+			}
+			return wait
+		}
+	}
+	wait := t.minBackoff << uint(attempt-1)
+	if wait > t.maxBackoff || (wait <= 0 && t.minBackoff > 0) {
+		wait = t.maxBackoff
+	}
+	if half := int64(wait / 2); half > 0 {
+		wait = wait/2 + time.Duration(rand.Int63n(half+1))
+	}
+	return wait
+}
+
+// newTLSConfig builds the TLS configuration for connections to the main host from the provider's CA, client
+// certificate and server name settings.
+func newTLSConfig(config providerData) (*tls.Config, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	tlsConfig := &tls.Config{}
+
+	if !config.CACertFile.Null || !config.CACertPEM.Null {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !config.CACertFile.Null && len(config.CACertFile.Value) > 0 {
+			pem, err := os.ReadFile(config.CACertFile.Value)
+			if err != nil {
+				diags.AddAttributeError(
+					path.Root("ca_cert_file"),
+					"Unable to read CA certificates",
+					"Could not read "+config.CACertFile.Value+": "+err.Error(),
+				)
+				return nil, diags
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				diags.AddAttributeError(
+					path.Root("ca_cert_file"),
+					"Invalid CA certificates",
+					"No PEM encoded certificates were found in "+config.CACertFile.Value,
+				)
+				return nil, diags
+			}
+		}
+		if !config.CACertPEM.Null && len(config.CACertPEM.Value) > 0 {
+			if !pool.AppendCertsFromPEM([]byte(config.CACertPEM.Value)) {
+				diags.AddAttributeError(
+					path.Root("ca_cert_pem"),
+					"Invalid CA certificates",
+					"No PEM encoded certificates were found in ca_cert_pem",
+				)
+				return nil, diags
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	hasCert := !config.ClientCert.Null && len(config.ClientCert.Value) > 0
+	hasKey := !config.ClientKey.Null && len(config.ClientKey.Value) > 0
+	if hasCert != hasKey {
+		diags.AddAttributeError(
+			path.Root("client_cert"),
+			"Incomplete client certificate",
+			"client_cert and client_key must be set together",
+		)
+		return nil, diags
+	}
+	if hasCert {
+		cert, err := tls.X509KeyPair([]byte(config.ClientCert.Value), []byte(config.ClientKey.Value))
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("client_cert"),
+				"Invalid client certificate",
+				"Could not load client_cert and client_key: "+err.Error(),
+			)
+			return nil, diags
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if !config.TLSServerName.Null {
+		tlsConfig.ServerName = config.TLSServerName.Value
+	}
+	return tlsConfig, diags
+}
+
+// newProxyFunc returns the proxy selection for requests to the main host. proxy_url is used for both HTTP and HTTPS
+// when set, otherwise the standard proxy environment variables are. no_proxy replaces NO_PROXY in either case.
+func newProxyFunc(config providerData) (func(*http.Request) (*url.URL, error), diag.Diagnostics) {
+	var diags diag.Diagnostics
+	proxyConfig := httpproxy.FromEnvironment()
+	if !config.ProxyUrl.Null && len(config.ProxyUrl.Value) > 0 {
+		proxyUrl, err := url.Parse(config.ProxyUrl.Value)
+		if err != nil || proxyUrl.Host == "" {
+			diags.AddAttributeError(
+				path.Root("proxy_url"),
+				"Invalid proxy URL",
+				"proxy_url must be an absolute URL such as http://proxy.example.com:3128",
+			)
+			return nil, diags
+		}
+		proxyConfig.HTTPProxy = config.ProxyUrl.Value
+		proxyConfig.HTTPSProxy = config.ProxyUrl.Value
+	}
+	if !config.NoProxy.Null {
+		proxyConfig.NoProxy = config.NoProxy.Value
+	}
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, diags
+}
+
+// advancedAuthTransport signs each request for an advanced API key. The Authorization header is the SHA256 of the key,
+// a random nonce and the current timestamp, which are sent alongside it. It is wrapped by the retry transport so
+// every attempt is signed afresh.
+type advancedAuthTransport struct {
+	base   http.RoundTripper
+	apiKey string
+}
+
+const nonceChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func (t *advancedAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	nonce := make([]byte, 64)
+	if _, err := cryptorand.Read(nonce); err != nil {
+		return nil, err
+	}
+	for i := range nonce {
+		nonce[i] = nonceChars[int(nonce[i])%len(nonceChars)]
+	}
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	hash := sha256.Sum256([]byte(t.apiKey + string(nonce) + timestamp))
+
+	req = req.Clone(req.Context())
+	req.Header.Set("x-xdr-nonce", string(nonce))
+	req.Header.Set("x-xdr-timestamp", timestamp)
+	req.Header.Set("Authorization", hex.EncodeToString(hash[:]))
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,214 @@
+package xsoar
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc lets a function stand in for the transport below the one under test
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// attempt is the outcome of one request sent through the retry transport: a status code, or an error
+type attempt struct {
+	status int
+	err    error
+}
+
+var (
+	errDial  = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	errRead  = &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	errProxy = &net.OpError{Op: "proxyconnect", Net: "tcp", Err: errors.New("connection refused")}
+	errDNS   = &net.DNSError{Err: "no such host", Name: "xsoar.example.com"}
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		noRetry  bool
+		attempts []attempt
+		want     int
+		wantErr  bool
+	}{
+		{"get succeeds", http.MethodGet, false, []attempt{{status: 200}}, 1, false},
+		{"get retried on bad gateway", http.MethodGet, false, []attempt{{status: 502}, {status: 200}}, 2, false},
+		{"get retried on connection reset", http.MethodGet, false, []attempt{{err: errRead}, {status: 200}}, 2, false},
+		{"get not retried on bad request", http.MethodGet, false, []attempt{{status: 400}, {status: 200}}, 1, false},
+		{"get gives up after max attempts", http.MethodGet, false, []attempt{{status: 502}, {status: 502}, {status: 502}, {status: 200}}, 3, false},
+		{"delete retried on gateway timeout", http.MethodDelete, false, []attempt{{status: 504}, {status: 200}}, 2, false},
+		{"post not retried on bad gateway", http.MethodPost, false, []attempt{{status: 502}, {status: 200}}, 1, false},
+		{"post not retried on connection reset", http.MethodPost, false, []attempt{{err: errRead}, {status: 200}}, 1, true},
+		{"post retried on too many requests", http.MethodPost, false, []attempt{{status: 429}, {status: 200}}, 2, false},
+		{"post retried on service unavailable", http.MethodPost, false, []attempt{{status: 503}, {status: 200}}, 2, false},
+		{"post retried on refused connection", http.MethodPost, false, []attempt{{err: errDial}, {status: 200}}, 2, false},
+		{"post retried on refused proxy connection", http.MethodPost, false, []attempt{{err: errProxy}, {status: 200}}, 2, false},
+		{"post retried on unresolved host", http.MethodPost, false, []attempt{{err: errDNS}, {status: 200}}, 2, false},
+		{"put not retried on gateway timeout", http.MethodPut, false, []attempt{{status: 504}, {status: 200}}, 1, false},
+		{"put retried on refused connection", http.MethodPut, false, []attempt{{err: errDial}, {status: 200}}, 2, false},
+		{"get sent once without retries", http.MethodGet, true, []attempt{{status: 502}, {status: 200}}, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent int
+			base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				body, err := io.ReadAll(req.Body)
+				if err != nil || string(body) != `{"name":"foo"}` {
+					t.Errorf("attempt %d sent body %q, %v", sent+1, body, err)
+				}
+				next := tt.attempts[sent]
+				sent++
+				if next.err != nil {
+					return nil, next.err
+				}
+				return &http.Response{StatusCode: next.status, Header: http.Header{}, Body: http.NoBody}, nil
+			})
+			transport := &retryTransport{base: base, maxAttempts: 3, statusCodes: map[int]bool{429: true, 502: true, 503: true, 504: true}}
+
+			ctx := context.Background()
+			if tt.noRetry {
+				ctx = withoutRetries(ctx)
+			}
+			req, _ := http.NewRequestWithContext(ctx, tt.method, "https://xsoar.example.com/incidenttype", strings.NewReader(`{"name":"foo"}`))
+			resp, err := transport.RoundTrip(req)
+			if sent != tt.want {
+				t.Errorf("sent %d attempts, want %d", sent, tt.want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if err == nil {
+				if last := tt.attempts[sent-1]; resp.StatusCode != last.status {
+					t.Errorf("got status %d, want %d", resp.StatusCode, last.status)
+				}
+			}
+		})
+	}
+}
+
+func TestNotSent(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"refused connection", errDial, true},
+		{"refused proxy connection", errProxy, true},
+		{"unresolved host", errDNS, true},
+		{"wrapped refused connection", &wrappedError{errDial}, true},
+		{"connection reset", errRead, false},
+		{"timeout", context.DeadlineExceeded, false},
+		{"unexpected EOF", io.ErrUnexpectedEOF, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notSent(tt.err); got != tt.want {
+				t.Errorf("notSent(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+type wrappedError struct {
+	err error
+}
+
+func (e *wrappedError) Error() string { return "Post: " + e.err.Error() }
+func (e *wrappedError) Unwrap() error { return e.err }
+
+func TestRetryBackoff(t *testing.T) {
+	transport := &retryTransport{minBackoff: time.Second, maxBackoff: 10 * time.Second}
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		min        time.Duration
+		max        time.Duration
+	}{
+		{"first attempt", 1, "", 500 * time.Millisecond, time.Second},
+		{"doubles", 3, "", 2 * time.Second, 4 * time.Second},
+		{"capped", 10, "", 5 * time.Second, 10 * time.Second},
+		{"overflow capped", 80, "", 5 * time.Second, 10 * time.Second},
+		{"retry after", 1, "7", 7 * time.Second, 7 * time.Second},
+		{"retry after capped", 1, "120", 10 * time.Second, 10 * time.Second},
+		{"retry after date ignored", 1, "Wed, 21 Oct 2015 07:28:00 GMT", 500 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			if got := transport.backoff(tt.attempt, resp); got < tt.min || got > tt.max {
+				t.Errorf("backoff(%d) = %s, want between %s and %s", tt.attempt, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestAdvancedAuthTransport(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey string
+	}{
+		{"alphanumeric key", "0123456789ABCDEFabcdef"},
+		{"key with symbols", "k3y+/=-_"},
+		{"empty key", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []*http.Request
+			base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				sent = append(sent, req)
+				return &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody}, nil
+			})
+			transport := &advancedAuthTransport{base: base, apiKey: tt.apiKey}
+
+			req, _ := http.NewRequest(http.MethodGet, "https://xsoar.example.com/about", nil)
+			req.Header.Set("x-xdr-auth-id", "7")
+			before := time.Now().UnixNano() / int64(time.Millisecond)
+			for i := 0; i < 2; i++ {
+				if _, err := transport.RoundTrip(req); err != nil {
+					t.Fatalf("RoundTrip: %s", err)
+				}
+			}
+			after := time.Now().UnixNano() / int64(time.Millisecond)
+
+			if req.Header.Get("Authorization") != "" {
+				t.Errorf("the caller's request was modified")
+			}
+			for _, signed := range sent {
+				nonce := signed.Header.Get("x-xdr-nonce")
+				timestamp := signed.Header.Get("x-xdr-timestamp")
+				if len(nonce) != 64 || strings.Trim(nonce, nonceChars) != "" {
+					t.Errorf("nonce %q is not 64 alphanumeric characters", nonce)
+				}
+				if ms, err := strconv.ParseInt(timestamp, 10, 64); err != nil || ms < before || ms > after {
+					t.Errorf("timestamp %q is not the time of the request in milliseconds", timestamp)
+				}
+				hash := sha256.Sum256([]byte(tt.apiKey + nonce + timestamp))
+				if got, want := signed.Header.Get("Authorization"), hex.EncodeToString(hash[:]); got != want {
+					t.Errorf("Authorization = %q, want %q", got, want)
+				}
+				if got := signed.Header.Get("x-xdr-auth-id"); got != "7" {
+					t.Errorf("x-xdr-auth-id = %q, want it passed through", got)
+				}
+			}
+			if sent[0].Header.Get("x-xdr-nonce") == sent[1].Header.Get("x-xdr-nonce") {
+				t.Errorf("both requests were signed with the same nonce")
+			}
+		})
+	}
+}
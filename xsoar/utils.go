@@ -1,5 +1,12 @@
 package xsoar
 
+import (
+	"encoding/json"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"reflect"
+)
+
 func equalSliceString(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -11,3 +18,97 @@ func equalSliceString(a, b []string) bool {
 	}
 	return true
 }
+
+// stringValue returns v if it is a string, or "" otherwise
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// optionalString returns v as a string attribute, which is null when v is not a string or is empty
+func optionalString(v interface{}) types.String {
+	if s, ok := v.(string); ok && len(s) > 0 {
+		return types.String{Value: s}
+	}
+	return types.String{Null: true}
+}
+
+// int64Value returns v, a number decoded from JSON, as an int64, or 0 if it is not a number
+func int64Value(v interface{}) int64 {
+	f, _ := v.(float64)
+	return int64(f)
+}
+
+// stringSet returns the strings in v, a list decoded from JSON, as a set attribute
+func stringSet(v interface{}) types.Set {
+	elems := []attr.Value{}
+	for _, elem := range listOrEmpty(v) {
+		if s, ok := elem.(string); ok {
+			elems = append(elems, types.String{Value: s})
+		}
+	}
+	return types.Set{Elems: elems, ElemType: types.StringType}
+}
+
+// listOrEmpty returns v if it is a list decoded from JSON, or an empty list otherwise
+func listOrEmpty(v interface{}) []interface{} {
+	if l, ok := v.([]interface{}); ok {
+		return l
+	}
+	return []interface{}{}
+}
+
+// jsonLike returns value, decoded from JSON, as a JSON string attribute that only has the object keys prior does, so
+// that attributes the server adds to what was configured do not show as a diff. prior itself is returned when the two
+// mean the same JSON, keeping its formatting. value is returned whole when prior is null or unknown, as on import.
+func jsonLike(value interface{}, prior types.String) (types.String, error) {
+	if !prior.Null && !prior.Unknown {
+		var priorValue interface{}
+		if err := json.Unmarshal([]byte(prior.Value), &priorValue); err == nil {
+			value = pruneToShape(value, priorValue)
+			if reflect.DeepEqual(value, priorValue) {
+				return prior, nil
+			}
+		}
+	}
+	valueJson, err := json.Marshal(value)
+	if err != nil {
+		return types.String{}, err
+	}
+	return types.String{Value: string(valueJson)}, nil
+}
+
+// pruneToShape returns value, decoded from JSON, without the object keys that shape does not have. Lists are pruned
+// element by element, and elements beyond the end of shape are kept whole.
+func pruneToShape(value interface{}, shape interface{}) interface{} {
+	switch shape := shape.(type) {
+	case map[string]interface{}:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value
+		}
+		pruned := map[string]interface{}{}
+		for key, elem := range object {
+			if shapeElem, ok := shape[key]; ok {
+				pruned[key] = pruneToShape(elem, shapeElem)
+			}
+		}
+		return pruned
+	case []interface{}:
+		list, ok := value.([]interface{})
+		if !ok {
+			return value
+		}
+		pruned := make([]interface{}, len(list))
+		for i, elem := range list {
+			if i < len(shape) {
+				pruned[i] = pruneToShape(elem, shape[i])
+			} else {
+				pruned[i] = elem
+			}
+		}
+		return pruned
+	default:
+		return value
+	}
+}
@@ -0,0 +1,78 @@
+package xsoar
+
+import (
+	"encoding/json"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"reflect"
+	"testing"
+)
+
+// decodeJSON decodes a JSON document the way API responses are, failing the test if it is invalid
+func decodeJSON(t *testing.T, document string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		t.Fatalf("invalid JSON %s: %s", document, err)
+	}
+	return value
+}
+
+func TestPruneToShape(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		shape string
+		want  string
+	}{
+		{"same", `{"a":1}`, `{"a":1}`, `{"a":1}`},
+		{"added keys dropped", `{"a":1,"b":2}`, `{"a":1}`, `{"a":1}`},
+		{"changed values kept", `{"a":2}`, `{"a":1}`, `{"a":2}`},
+		{"removed keys stay removed", `{}`, `{"a":1}`, `{}`},
+		{"nested objects", `{"a":{"b":1,"c":2}}`, `{"a":{"b":1}}`, `{"a":{"b":1}}`},
+		{"list elements pruned", `[{"a":1,"id":"x"},{"a":2,"id":"y"}]`, `[{"a":1},{"a":2}]`, `[{"a":1},{"a":2}]`},
+		{"added list elements kept whole", `[{"a":1,"id":"x"},{"a":2,"id":"y"}]`, `[{"a":1}]`, `[{"a":1},{"a":2,"id":"y"}]`},
+		{"removed list elements stay removed", `[{"a":1}]`, `[{"a":1},{"a":2}]`, `[{"a":1}]`},
+		{"object replaced by list", `[1]`, `{"a":1}`, `[1]`},
+		{"list replaced by object", `{"a":1}`, `[1]`, `{"a":1}`},
+		{"scalar shape keeps value", `{"a":1}`, `"a"`, `{"a":1}`},
+		{"null shape keeps value", `{"a":1}`, `null`, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pruneToShape(decodeJSON(t, tt.value), decodeJSON(t, tt.shape))
+			if want := decodeJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("pruneToShape(%s, %s) = %v, want %s", tt.value, tt.shape, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJsonLike(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		prior types.String
+		want  string
+	}{
+		{"no prior", `{"b":1,"a":[1,2]}`, types.String{Null: true}, `{"a":[1,2],"b":1}`},
+		{"unknown prior", `{"b":1,"a":[1,2]}`, types.String{Unknown: true}, `{"a":[1,2],"b":1}`},
+		{"formatting kept", `{"b":1,"a":[1,2]}`, types.String{Value: "{\n  \"a\": [1, 2],\n  \"b\": 1.0\n}"}, "{\n  \"a\": [1, 2],\n  \"b\": 1.0\n}"},
+		{"server-added keys ignored", `{"a":1,"version":3,"modified":"2022-01-01"}`, types.String{Value: `{ "a": 1 }`}, `{ "a": 1 }`},
+		{"server-added column attributes ignored", `[{"key":"a","isDefault":false,"width":150},{"key":"b","isDefault":true}]`, types.String{Value: `[{"key":"a"},{"key":"b"}]`}, `[{"key":"a"},{"key":"b"}]`},
+		{"changed value", `{"a":2,"version":3}`, types.String{Value: `{ "a": 1 }`}, `{"a":2}`},
+		{"removed key", `{"version":3}`, types.String{Value: `{ "a": 1 }`}, `{}`},
+		{"added list element", `[{"key":"a","width":150},{"key":"b","width":150}]`, types.String{Value: `[{"key":"a"}]`}, `[{"key":"a"},{"key":"b","width":150}]`},
+		{"invalid prior", `{"a":1}`, types.String{Value: `{a: 1}`}, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonLike(decodeJSON(t, tt.value), tt.prior)
+			if err != nil {
+				t.Fatalf("jsonLike: %s", err)
+			}
+			if got.Null || got.Unknown || got.Value != tt.want {
+				t.Errorf("jsonLike(%s, %v) = %v, want %s", tt.value, tt.prior, got, tt.want)
+			}
+		})
+	}
+}